@@ -0,0 +1,304 @@
+// Package agent implements OSTIS agents in Go. Manager listens for actions added to
+// action_initiated, runs handlers registered for their classes and finishes actions
+// with result structure and status class
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	sc "github.com/temapriemnik/go-sc-client"
+)
+
+// DefaultConcurrency is a default number of actions handled by agent at once
+const DefaultConcurrency = 4
+
+// ErrUnsuccessful is returned by handler to finish action unsuccessfully instead of with error
+var ErrUnsuccessful = errors.New("action finished unsuccessfully")
+
+// HandlerFunc handles action. Returned structure is connected with action by nrel_result and
+// may be invalid addr. Errors wrapping ErrUnsuccessful finish action unsuccessfully,
+// other errors, panics and timeouts finish it with error
+type HandlerFunc func(ctx context.Context, action *Action) (sc.ScAddr, error)
+
+// Options configures agent
+type Options struct {
+	// Concurrency is a number of actions handled at once, DefaultConcurrency if not positive
+	Concurrency int
+	// Timeout limits handling of one action when positive
+	Timeout time.Duration
+}
+
+// Action represents initiated action passed to handler
+type Action struct {
+	Client *sc.ScClient
+	Addr   sc.ScAddr
+	Class  sc.ScAddr
+	// Args are arguments of action in order of rrel_1, rrel_2, ... roles
+	Args []sc.ScAddr
+}
+
+// Arg returns argument with role rrel_n, invalid addr if it is missing
+func (a *Action) Arg(n int) sc.ScAddr {
+	if n < 1 || n > len(a.Args) {
+		return sc.ScAddr{}
+	}
+	return a.Args[n-1]
+}
+
+// agent represents handler registered for action class
+type agent struct {
+	class   sc.ScAddr
+	handler HandlerFunc
+	timeout time.Duration
+	slots   chan struct{}
+	// order is a number of agent in order of registration
+	order int
+}
+
+// Manager runs registered agents. It is safe for concurrent use
+type Manager struct {
+	client *sc.ScClient
+
+	mu       sync.Mutex
+	agents   map[int64]*agent
+	listener *sc.ScEventListener
+	stopping bool
+	wg       sync.WaitGroup
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewManager creates new agents manager
+func NewManager(client *sc.ScClient) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		client: client,
+		agents: make(map[int64]*agent),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Register registers handler for action class passed as ScAddr or system identifier.
+// Agents may be registered before and after Start
+func (m *Manager) Register(actionClass interface{}, handler HandlerFunc, opts Options) error {
+	if handler == nil {
+		return sc.CommonError(sc.ErrInvalidParameters, "handler should not be nil")
+	}
+
+	class, err := m.resolveClass(actionClass)
+	if err != nil {
+		return err
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.agents[class.Value]; exists {
+		return sc.CommonError(sc.ErrInvalidParameters, fmt.Sprintf("agent for action class %v is already registered", class))
+	}
+	m.agents[class.Value] = &agent{
+		class:   class,
+		handler: handler,
+		timeout: opts.Timeout,
+		slots:   make(chan struct{}, concurrency),
+		order:   len(m.agents),
+	}
+	return nil
+}
+
+func (m *Manager) resolveClass(actionClass interface{}) (sc.ScAddr, error) {
+	switch v := actionClass.(type) {
+	case sc.ScAddr:
+		if !v.IsValid() {
+			return sc.ScAddr{}, sc.InvalidValueError("invalid addr of action class")
+		}
+		return v, nil
+	case string:
+		return m.client.Keynode(v)
+	default:
+		return sc.ScAddr{}, sc.CommonError(sc.ErrInvalidParameters, "action class should be ScAddr or system identifier")
+	}
+}
+
+// Start subscribes for actions added to action_initiated
+func (m *Manager) Start() error {
+	keynodes, err := m.client.CommonKeynodes()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopping {
+		return errors.New("failed to start agents: manager is shut down")
+	}
+	if m.listener != nil {
+		return nil
+	}
+
+	listener, err := m.client.AddListener(keynodes.ActionInitiated, sc.ScEventAddOutgoingEdge, m.onInitiated, nil)
+	if err != nil {
+		return err
+	}
+	m.listener = listener
+	return nil
+}
+
+// Shutdown stops accepting actions and waits for running handlers. When ctx is done
+// before handlers return, their contexts are cancelled and ctx error is returned
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.stopping = true
+	listener := m.listener
+	m.listener = nil
+	m.mu.Unlock()
+
+	var err error
+	if listener != nil {
+		err = listener.Close()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		m.cancel()
+		return err
+	case <-ctx.Done():
+		m.cancel()
+		return ctx.Err()
+	}
+}
+
+func (m *Manager) onInitiated(elAddr, edge, other sc.ScAddr, eventID int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopping {
+		return
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.handle(other)
+	}()
+}
+
+// handle finds agent for class of action and runs it
+func (m *Manager) handle(action sc.ScAddr) {
+	agent, err := m.findAgent(action)
+	if err != nil {
+		log.Printf("Failed to find agent for action %v: %v", action, err)
+		return
+	}
+	if agent == nil {
+		return
+	}
+
+	select {
+	case agent.slots <- struct{}{}:
+	case <-m.ctx.Done():
+		return
+	}
+
+	args, err := m.client.ActionArguments(action)
+	if err != nil {
+		<-agent.slots
+		log.Printf("Failed to get arguments of action %v: %v", action, err)
+		m.finish(action, sc.ScActionFinishedWithError, sc.ScAddr{})
+		return
+	}
+
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if agent.timeout > 0 {
+		ctx, cancel = context.WithTimeout(m.ctx, agent.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(m.ctx)
+	}
+	defer cancel()
+
+	type outcome struct {
+		result sc.ScAddr
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		// Slot is released when handler returns, even if it outlives timeout
+		defer func() { <-agent.slots }()
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{err: fmt.Errorf("agent panic: %v", r)}
+			}
+		}()
+
+		result, err := agent.handler(ctx, &Action{Client: m.client, Addr: action, Class: agent.class, Args: args})
+		done <- outcome{result: result, err: err}
+	}()
+
+	var res outcome
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		res = outcome{err: ctx.Err()}
+	}
+
+	switch {
+	case res.err == nil:
+		m.finish(action, sc.ScActionFinishedSuccessfully, res.result)
+	case errors.Is(res.err, ErrUnsuccessful):
+		m.finish(action, sc.ScActionFinishedUnsuccessfully, res.result)
+	default:
+		log.Printf("Agent for action class %v failed on action %v: %v", agent.class, action, res.err)
+		m.finish(action, sc.ScActionFinishedWithError, res.result)
+	}
+}
+
+// findAgent returns agent registered for one of classes of action, nil if there is no one.
+// When action belongs to several classes, agent registered first is chosen
+func (m *Manager) findAgent(action sc.ScAddr) (*agent, error) {
+	template := &sc.ScTemplate{}
+	template.Triple(
+		[]interface{}{sc.ScType{Value: sc.ScTypeNodeVar}, "_class"},
+		sc.ScType{Value: sc.ScTypeArcPosVarPerm},
+		action,
+	)
+
+	set, err := m.client.TemplateSearchSet(template)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *agent
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, class := range set.Distinct("_class") {
+		if agent, exists := m.agents[class.Value]; exists && (found == nil || agent.order < found.order) {
+			found = agent
+		}
+	}
+	return found, nil
+}
+
+func (m *Manager) finish(action sc.ScAddr, status sc.ScActionStatus, result sc.ScAddr) {
+	if err := m.client.FinishAction(action, status, result); err != nil {
+		log.Printf("Failed to finish action %v: %v", action, err)
+	}
+}
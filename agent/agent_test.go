@@ -0,0 +1,229 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sc "github.com/temapriemnik/go-sc-client"
+	"github.com/temapriemnik/go-sc-client/agent"
+	"github.com/temapriemnik/go-sc-client/agenttest"
+)
+
+func TestManagerFinishesActionByHandlerOutcome(t *testing.T) {
+	kit := agenttest.New(t)
+	for _, class := range []string{"action_fail", "action_panic", "action_slow", "action_refuse"} {
+		kit.Keynode(class, sc.ScTypeNodeConstClass)
+	}
+
+	kit.Register("action_fail", func(ctx context.Context, action *agent.Action) (sc.ScAddr, error) {
+		return sc.ScAddr{}, errors.New("handler failed")
+	}, agent.Options{})
+	kit.Register("action_panic", func(ctx context.Context, action *agent.Action) (sc.ScAddr, error) {
+		panic("handler panicked")
+	}, agent.Options{})
+	kit.Register("action_slow", func(ctx context.Context, action *agent.Action) (sc.ScAddr, error) {
+		<-ctx.Done()
+		return sc.ScAddr{}, nil
+	}, agent.Options{Timeout: 50 * time.Millisecond})
+	kit.Register("action_refuse", func(ctx context.Context, action *agent.Action) (sc.ScAddr, error) {
+		return sc.ScAddr{}, agent.ErrUnsuccessful
+	}, agent.Options{})
+
+	for class, status := range map[string]sc.ScActionStatus{
+		"action_fail":   sc.ScActionFinishedWithError,
+		"action_panic":  sc.ScActionFinishedWithError,
+		"action_slow":   sc.ScActionFinishedWithError,
+		"action_refuse": sc.ScActionFinishedUnsuccessfully,
+	} {
+		if result := kit.InitiateAction(class); result.Status != status || result.Result.IsValid() {
+			t.Errorf("unexpected result of %s: %+v", class, result)
+		}
+	}
+}
+
+func TestManagerChoosesAgentRegisteredFirst(t *testing.T) {
+	kit := agenttest.New(t)
+	classes := kit.LoadSCs(`
+		action_specific <- sc_node_class;;
+		action_general <- sc_node_class;;
+	`)
+
+	handled := make(chan string, 10)
+	for _, class := range []string{"action_general", "action_specific"} {
+		class := class
+		kit.Register(class, func(ctx context.Context, action *agent.Action) (sc.ScAddr, error) {
+			handled <- class
+			return sc.ScAddr{}, nil
+		}, agent.Options{})
+	}
+
+	keynodes, err := kit.Client.CommonKeynodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		// Action is found in action_specific before action_general
+		action, err := kit.Client.CreateAction(classes["action_specific"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		construction := &sc.ScConstruction{}
+		if err := construction.CreateEdge(sc.ScType{Value: sc.ScTypeArcPosConstPerm}, classes["action_general"], action, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := construction.CreateEdge(sc.ScType{Value: sc.ScTypeArcPosConstPerm}, keynodes.ActionInitiated, action, ""); err != nil {
+			t.Fatal(err)
+		}
+		kit.Construct(construction)
+
+		select {
+		case class := <-handled:
+			if class != "action_general" {
+				t.Fatalf("action is handled by agent of %s", class)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("action is not handled")
+		}
+	}
+}
+
+func TestManagerLimitsConcurrency(t *testing.T) {
+	kit := agenttest.New(t)
+	kit.Keynode("action_limited", sc.ScTypeNodeConstClass)
+
+	var running, maxRunning atomic.Int64
+	kit.Register("action_limited", func(ctx context.Context, action *agent.Action) (sc.ScAddr, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			max := maxRunning.Load()
+			if current <= max || maxRunning.CompareAndSwap(max, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return sc.ScAddr{}, nil
+	}, agent.Options{Concurrency: 2})
+
+	results := make(chan *sc.ScActionResult, 6)
+	for i := 0; i < cap(results); i++ {
+		go func() {
+			result, err := kit.Client.InitiateAction(context.Background(), "action_limited")
+			if err != nil {
+				t.Error(err)
+			}
+			results <- result
+		}()
+	}
+	for i := 0; i < cap(results); i++ {
+		if result := <-results; result == nil || !result.Succeeded() {
+			t.Errorf("unexpected action result %+v", result)
+		}
+	}
+	if maxRunning.Load() != 2 {
+		t.Errorf("expected 2 handlers at once, got %d", maxRunning.Load())
+	}
+}
+
+func TestManagerRegister(t *testing.T) {
+	kit := agenttest.New(t)
+	class := kit.Keynode("action_registered", sc.ScTypeNodeConstClass)
+	manager := agent.NewManager(kit.Client)
+
+	handler := func(ctx context.Context, action *agent.Action) (sc.ScAddr, error) {
+		return sc.ScAddr{}, nil
+	}
+	if err := manager.Register(class, nil, agent.Options{}); err == nil || !strings.HasPrefix(err.Error(), sc.ErrInvalidParameters.Error()) {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+	if err := manager.Register(class, handler, agent.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Register("action_registered", handler, agent.Options{}); err == nil || !strings.HasPrefix(err.Error(), sc.ErrInvalidParameters.Error()) {
+		t.Errorf("agent is registered twice: %v", err)
+	}
+	if err := manager.Register(sc.ScAddr{}, handler, agent.Options{}); err == nil {
+		t.Error("agent is registered for invalid class")
+	}
+	if err := manager.Register(1, handler, agent.Options{}); err == nil || !strings.HasPrefix(err.Error(), sc.ErrInvalidParameters.Error()) {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+	if err := manager.Register("action_missing", handler, agent.Options{}); err == nil {
+		t.Error("agent is registered for missing class")
+	}
+}
+
+func TestManagerShutdown(t *testing.T) {
+	kit := agenttest.New(t)
+	kit.Keynode("action_blocking", sc.ScTypeNodeConstClass)
+	manager := agent.NewManager(kit.Client)
+
+	var calls atomic.Int64
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	if err := manager.Register("action_blocking", func(ctx context.Context, action *agent.Action) (sc.ScAddr, error) {
+		if calls.Add(1) > 1 {
+			return sc.ScAddr{}, nil
+		}
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return sc.ScAddr{}, ctx.Err()
+	}, agent.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	keynodes, err := kit.Client.CommonKeynodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	class, err := kit.Client.Keynode("action_blocking")
+	if err != nil {
+		t.Fatal(err)
+	}
+	initiate := func() {
+		action, err := kit.Client.CreateAction(class)
+		if err != nil {
+			t.Fatal(err)
+		}
+		construction := &sc.ScConstruction{}
+		if err := construction.CreateEdge(sc.ScType{Value: sc.ScTypeArcPosConstPerm}, keynodes.ActionInitiated, action, ""); err != nil {
+			t.Fatal(err)
+		}
+		kit.Construct(construction)
+	}
+	initiate()
+	<-started
+
+	// Running handler is cancelled when shutdown times out
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := manager.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline error, got %v", err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler is not cancelled")
+	}
+
+	// Actions initiated after shutdown are not handled
+	initiate()
+	time.Sleep(50 * time.Millisecond)
+	if calls.Load() != 1 {
+		t.Errorf("action is handled after shutdown")
+	}
+	if err := manager.Start(); err == nil {
+		t.Error("manager is started after shutdown")
+	}
+	if err := manager.Shutdown(context.Background()); err != nil {
+		t.Errorf("second shutdown failed: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+// Package agenttest helps to test agents and event handlers without sc-machine.
+// It runs in-process server keeping knowledge base in memory, loads SCs fixtures,
+// initiates actions and asserts on knowledge base with templates
+package agenttest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sc "github.com/temapriemnik/go-sc-client"
+	"github.com/temapriemnik/go-sc-client/agent"
+)
+
+// DefaultTimeout is a default time Kit waits for action to finish
+const DefaultTimeout = 5 * time.Second
+
+// Kit runs server with connected client. Everything is closed on test cleanup
+type Kit struct {
+	TB      testing.TB
+	Server  *Server
+	Client  *sc.ScClient
+	Timeout time.Duration
+
+	agents *agent.Manager
+}
+
+// New starts server and client for test
+func New(tb testing.TB) *Kit {
+	tb.Helper()
+
+	server := NewServer()
+	k := &Kit{
+		TB:      tb,
+		Server:  server,
+		Client:  sc.NewScClient(server.URL()),
+		Timeout: DefaultTimeout,
+	}
+
+	tb.Cleanup(func() {
+		if k.agents != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), k.Timeout)
+			defer cancel()
+			if err := k.agents.Shutdown(ctx); err != nil {
+				tb.Errorf("failed to shut down agents: %v", err)
+			}
+		}
+		k.Client.Close()
+		k.Server.Close()
+	})
+	return k
+}
+
+// Keynode returns element with system identifier, node of type is created if it is missing
+func (k *Kit) Keynode(idtf string, t int) sc.ScAddr {
+	k.TB.Helper()
+
+	keynodes, err := k.Client.ResolveKeynodesOrdered([]sc.KeynodeRequest{{Idtf: idtf, Type: sc.ScType{Value: t}}})
+	if err != nil {
+		k.TB.Fatalf("failed to resolve keynode %q: %v", idtf, err)
+	}
+	if !keynodes[0].Found {
+		k.TB.Fatalf("keynode %q is not found", idtf)
+	}
+	return keynodes[0].Addr
+}
+
+// LoadSCs creates elements described by SCs text, see LoadSCs for supported sentences
+func (k *Kit) LoadSCs(text string) map[string]sc.ScAddr {
+	k.TB.Helper()
+
+	addrs, err := LoadSCs(k.Client, text)
+	if err != nil {
+		k.TB.Fatalf("failed to load SCs: %v", err)
+	}
+	return addrs
+}
+
+// Construct creates elements of construction
+func (k *Kit) Construct(construction *sc.ScConstruction) []sc.ScAddr {
+	k.TB.Helper()
+
+	addrs, err := k.Client.CreateElements(construction)
+	if err != nil {
+		k.TB.Fatalf("failed to create construction:\n%s\n%v", construction.SCs(), err)
+	}
+	return addrs
+}
+
+// Agents returns started agents manager. It is shut down on test cleanup
+func (k *Kit) Agents() *agent.Manager {
+	k.TB.Helper()
+
+	if k.agents == nil {
+		manager := agent.NewManager(k.Client)
+		if err := manager.Start(); err != nil {
+			k.TB.Fatalf("failed to start agents: %v", err)
+		}
+		k.agents = manager
+	}
+	return k.agents
+}
+
+// Register registers handler for action class in agents manager
+func (k *Kit) Register(actionClass interface{}, handler agent.HandlerFunc, opts agent.Options) {
+	k.TB.Helper()
+
+	if err := k.Agents().Register(actionClass, handler, opts); err != nil {
+		k.TB.Fatalf("failed to register agent: %v", err)
+	}
+}
+
+// InitiateAction initiates action and waits until it is finished within Timeout
+func (k *Kit) InitiateAction(actionClass interface{}, args ...sc.ScAddr) *sc.ScActionResult {
+	k.TB.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), k.Timeout)
+	defer cancel()
+
+	result, err := k.Client.InitiateAction(ctx, actionClass, args...)
+	if err != nil {
+		k.TB.Fatalf("failed to perform action of class %v: %v", actionClass, err)
+	}
+	return result
+}
+
+// AssertTemplateExists checks that template has results and returns them
+func (k *Kit) AssertTemplateExists(template *sc.ScTemplate, params map[string]sc.ScAddr) []sc.ScTemplateResult {
+	k.TB.Helper()
+	return AssertTemplateExists(k.TB, k.Client, template, params)
+}
+
+// AssertNoMatch checks that template has no results
+func (k *Kit) AssertNoMatch(template *sc.ScTemplate, params map[string]sc.ScAddr) {
+	k.TB.Helper()
+	AssertNoMatch(k.TB, k.Client, template, params)
+}
+
+// AssertTemplateExists checks that template has results and returns them
+func AssertTemplateExists(tb testing.TB, client *sc.ScClient, template *sc.ScTemplate, params map[string]sc.ScAddr) []sc.ScTemplateResult {
+	tb.Helper()
+
+	results, err := client.TemplateSearchWithParams(template, params)
+	if err != nil {
+		tb.Fatalf("failed to search template:\n%s\n%v", template.SCs(), err)
+	}
+	if len(results) == 0 {
+		tb.Errorf("template has no results:\n%s\nparams: %v", template.SCs(), params)
+	}
+	return results
+}
+
+// AssertNoMatch checks that template has no results
+func AssertNoMatch(tb testing.TB, client *sc.ScClient, template *sc.ScTemplate, params map[string]sc.ScAddr) {
+	tb.Helper()
+
+	results, err := client.TemplateSearchWithParams(template, params)
+	if err != nil {
+		tb.Fatalf("failed to search template:\n%s\n%v", template.SCs(), err)
+	}
+	if len(results) > 0 {
+		tb.Errorf("template has %d unexpected results:\n%s\nfirst: %v", len(results), template.SCs(), results[0])
+	}
+}
@@ -0,0 +1,95 @@
+package agenttest_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	sc "github.com/temapriemnik/go-sc-client"
+	"github.com/temapriemnik/go-sc-client/agent"
+	"github.com/temapriemnik/go-sc-client/agenttest"
+)
+
+func TestKitRunsAgent(t *testing.T) {
+	kit := agenttest.New(t)
+	fixture := kit.LoadSCs(`
+		action_greet <- sc_node_class;;
+		nrel_greeting <- sc_node_norole_relation;;
+		..person => nrel_main_idtf: [Alice];;
+	`)
+
+	kit.Register("action_greet", func(ctx context.Context, action *agent.Action) (sc.ScAddr, error) {
+		names, err := action.Client.GetMainIdtfs([]sc.ScAddr{action.Arg(1)}, nil)
+		if err != nil {
+			return sc.ScAddr{}, err
+		}
+		if names[0] == "" {
+			return sc.ScAddr{}, fmt.Errorf("%w: person has no name", agent.ErrUnsuccessful)
+		}
+
+		template := &sc.ScTemplate{}
+		template.TripleWithRelation(
+			action.Arg(1),
+			sc.ScType{Value: sc.ScTypeDEdgeVar},
+			[]interface{}{sc.ScType{Value: sc.ScTypeNodeVarStruct}, "_greeting"},
+			sc.ScType{Value: sc.ScTypeArcPosVarPerm},
+			fixture["nrel_greeting"],
+		)
+		result, err := action.Client.TemplateGenerate(template, nil)
+		if err != nil {
+			return sc.ScAddr{}, err
+		}
+		return result.Get("_greeting"), nil
+	}, agent.Options{})
+
+	result := kit.InitiateAction("action_greet", fixture["..person"])
+	if !result.Succeeded() || !result.Result.IsValid() {
+		t.Fatalf("unexpected action result %+v", result)
+	}
+
+	template := &sc.ScTemplate{}
+	template.TripleWithRelation(
+		fixture["..person"],
+		sc.ScType{Value: sc.ScTypeDEdgeVar},
+		result.Result,
+		sc.ScType{Value: sc.ScTypeArcPosVarPerm},
+		fixture["nrel_greeting"],
+	)
+	kit.AssertTemplateExists(template, nil)
+
+	// Action with argument without name is finished unsuccessfully and changes nothing
+	anonymous := kit.LoadSCs(`..anonymous <- sc_node;;`)["..anonymous"]
+	if result := kit.InitiateAction("action_greet", anonymous); result.Status != sc.ScActionFinishedUnsuccessfully {
+		t.Errorf("expected unsuccessful action, got %v", result.Status)
+	}
+	greeting := &sc.ScTemplate{}
+	greeting.TripleWithRelation(
+		anonymous,
+		sc.ScType{Value: sc.ScTypeDEdgeVar},
+		sc.ScType{Value: sc.ScTypeNodeVarStruct},
+		sc.ScType{Value: sc.ScTypeArcPosVarPerm},
+		fixture["nrel_greeting"],
+	)
+	kit.AssertNoMatch(greeting, nil)
+}
+
+func TestKitActionArguments(t *testing.T) {
+	kit := agenttest.New(t)
+	args := kit.LoadSCs(`..first <- sc_node;; ..second <- sc_node;;`)
+	kit.Keynode("action_echo", sc.ScTypeNodeConstClass)
+
+	received := make(chan []sc.ScAddr, 1)
+	kit.Register("action_echo", func(ctx context.Context, action *agent.Action) (sc.ScAddr, error) {
+		received <- action.Args
+		return sc.ScAddr{}, nil
+	}, agent.Options{})
+
+	result := kit.InitiateAction("action_echo", args["..first"], args["..second"])
+	if !result.Succeeded() || result.Result.IsValid() {
+		t.Errorf("unexpected action result %+v", result)
+	}
+	got := <-received
+	if len(got) != 2 || !got[0].Equal(args["..first"]) || !got[1].Equal(args["..second"]) {
+		t.Errorf("unexpected arguments %v", got)
+	}
+}
@@ -0,0 +1,368 @@
+package agenttest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	sc "github.com/temapriemnik/go-sc-client"
+)
+
+// scsConnectors maps SCs connectors to edge types. Reversed connectors swap source and target
+var scsConnectors = map[string]struct {
+	t        int
+	reversed bool
+}{
+	"->":  {sc.ScTypeArcPosConstPerm, false},
+	"<-":  {sc.ScTypeArcPosConstPerm, true},
+	"-|>": {sc.ScTypeArcNegConstPerm, false},
+	"<|-": {sc.ScTypeArcNegConstPerm, true},
+	"-/>": {sc.ScTypeArcFuzConstPerm, false},
+	"</-": {sc.ScTypeArcFuzConstPerm, true},
+	"~>":  {sc.ScTypeArcPosConstTemp, false},
+	"<~":  {sc.ScTypeArcPosConstTemp, true},
+	"~|>": {sc.ScTypeArcNegConstTemp, false},
+	"<|~": {sc.ScTypeArcNegConstTemp, true},
+	"~/>": {sc.ScTypeArcFuzConstTemp, false},
+	"</~": {sc.ScTypeArcFuzConstTemp, true},
+	"=>":  {sc.ScTypeDEdgeConst, false},
+	"<=":  {sc.ScTypeDEdgeConst, true},
+	"<=>": {sc.ScTypeUEdgeCommon | sc.ScTypeConst, false},
+}
+
+// scsNodeTypes maps keynodes of node types to types set by `name <- keynode;;`
+var scsNodeTypes = map[string]int{
+	"sc_node":                 sc.ScTypeNodeConst,
+	"sc_node_tuple":           sc.ScTypeNodeConstTuple,
+	"sc_node_struct":          sc.ScTypeNodeConstStruct,
+	"sc_node_structure":       sc.ScTypeNodeConstStruct,
+	"sc_node_role_relation":   sc.ScTypeNodeConstRole,
+	"sc_node_norole_relation": sc.ScTypeNodeConstNoRole,
+	"sc_node_class":           sc.ScTypeNodeConstClass,
+	"sc_node_abstract":        sc.ScTypeNodeConst | sc.ScTypeNodeAbstract,
+	"sc_node_material":        sc.ScTypeNodeConst | sc.ScTypeNodeMaterial,
+	"sc_link":                 sc.ScTypeLinkConst,
+}
+
+// scsEdge represents parsed connector sentence
+type scsEdge struct {
+	alias      string
+	t          int
+	src, trg   string
+	attributes []string
+}
+
+// scsFixture collects elements of parsed SCs text
+type scsFixture struct {
+	types     map[string]int
+	links     map[string]string
+	edges     []scsEdge
+	names     []string
+	anonymous int
+}
+
+// LoadSCs creates elements described by SCs text and returns addresses of named elements.
+// Supported sentences are `a -> b;;`, `a => nrel: b; c;;`, `@e = (a -> b);;`, `a = [text];;`
+// and `a <- sc_node_class;;`. System identifiers are resolved as keynodes and created when missing,
+// names starting with ".." are local nodes, edges are referenced by "@" names and addresses by "#N"
+func LoadSCs(client *sc.ScClient, text string) (map[string]sc.ScAddr, error) {
+	f := &scsFixture{
+		types: make(map[string]int),
+		links: make(map[string]string),
+	}
+	for _, sentence := range splitSentences(text) {
+		if err := f.parse(sentence); err != nil {
+			return nil, fmt.Errorf("failed to parse %q: %w", sentence, err)
+		}
+	}
+	return f.create(client)
+}
+
+// splitSentences removes comments and splits text by ";;" outside of link contents
+func splitSentences(text string) []string {
+	var sentences []string
+	var current strings.Builder
+	inContent := false
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		switch {
+		case inContent:
+			if ch == ']' {
+				inContent = false
+			}
+		case ch == '[':
+			inContent = true
+		case ch == '/' && i+1 < len(text) && text[i+1] == '/':
+			for i < len(text) && text[i] != '\n' {
+				i++
+			}
+			continue
+		case ch == ';' && i+1 < len(text) && text[i+1] == ';':
+			if sentence := strings.TrimSpace(current.String()); sentence != "" {
+				sentences = append(sentences, sentence)
+			}
+			current.Reset()
+			i++
+			continue
+		}
+		current.WriteByte(ch)
+	}
+	if sentence := strings.TrimSpace(current.String()); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
+
+func isNameChar(ch rune) bool {
+	return unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '_' || ch == '.' || ch == '#' || ch == '@'
+}
+
+// tokenize splits sentence into names, connectors, link contents and punctuation
+func tokenize(sentence string) ([]string, error) {
+	var tokens []string
+	runes := []rune(sentence)
+	for i := 0; i < len(runes); {
+		ch := runes[i]
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case ch == '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unclosed link content")
+			}
+			tokens = append(tokens, string(runes[i:end+1]))
+			i = end + 1
+		case isNameChar(ch):
+			end := i
+			for end < len(runes) && isNameChar(runes[end]) {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		case ch == '(' || ch == ')' || ch == ';' || ch == ':':
+			tokens = append(tokens, string(ch))
+			i++
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !isNameChar(runes[end]) && runes[end] != '[' && runes[end] != '(' {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+func (f *scsFixture) declare(name string) {
+	if _, exists := f.types[name]; !exists {
+		f.types[name] = 0
+		f.names = append(f.names, name)
+	}
+}
+
+// element returns name of element token, link contents get generated names
+func (f *scsFixture) element(token string) (string, error) {
+	if strings.HasPrefix(token, "[") {
+		f.anonymous++
+		name := fmt.Sprintf("..link%d", f.anonymous)
+		f.declare(name)
+		f.links[name] = strings.TrimSuffix(strings.TrimPrefix(token, "["), "]")
+		return name, nil
+	}
+	if token == "" || !isNameChar([]rune(token)[0]) {
+		return "", fmt.Errorf("element expected instead of %q", token)
+	}
+	if !strings.HasPrefix(token, "@") {
+		f.declare(token)
+	}
+	return token, nil
+}
+
+func (f *scsFixture) parse(sentence string) error {
+	tokens, err := tokenize(sentence)
+	if err != nil {
+		return err
+	}
+	if len(tokens) < 3 {
+		return fmt.Errorf("sentence is too short")
+	}
+
+	// name = [content]
+	if tokens[1] == "=" && len(tokens) == 3 && strings.HasPrefix(tokens[2], "[") {
+		f.declare(tokens[0])
+		f.links[tokens[0]] = strings.TrimSuffix(strings.TrimPrefix(tokens[2], "["), "]")
+		return nil
+	}
+
+	// @edge = (src connector trg)
+	if tokens[1] == "=" {
+		if len(tokens) != 7 || tokens[2] != "(" || tokens[6] != ")" || !strings.HasPrefix(tokens[0], "@") {
+			return fmt.Errorf("edge definition should be @name = (src connector trg)")
+		}
+		return f.connect(tokens[0], tokens[3], tokens[4], tokens[5], nil)
+	}
+
+	// name <- sc_node_class
+	if t, isType := scsNodeTypes[tokens[2]]; isType && tokens[1] == "<-" && len(tokens) == 3 {
+		f.declare(tokens[0])
+		f.types[tokens[0]] = t
+		return nil
+	}
+
+	// src connector attr: trg; attr: trg
+	src := tokens[0]
+	connector := tokens[1]
+	var attributes []string
+	for i := 2; i < len(tokens); i++ {
+		switch {
+		case tokens[i] == ";":
+			attributes = nil
+		case i+1 < len(tokens) && tokens[i+1] == ":":
+			attributes = append(attributes, tokens[i])
+			i++
+		default:
+			if err := f.connect("", src, connector, tokens[i], attributes); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (f *scsFixture) connect(alias, src, connector, trg string, attributes []string) error {
+	c, known := scsConnectors[connector]
+	if !known {
+		return fmt.Errorf("unknown connector %q", connector)
+	}
+
+	src, err := f.element(src)
+	if err != nil {
+		return err
+	}
+	trg, err = f.element(trg)
+	if err != nil {
+		return err
+	}
+	for _, attribute := range attributes {
+		if strings.HasPrefix(attribute, "@") || strings.HasPrefix(attribute, "[") {
+			return fmt.Errorf("attribute %q should be node", attribute)
+		}
+		if _, err := f.element(attribute); err != nil {
+			return err
+		}
+	}
+
+	if c.reversed {
+		src, trg = trg, src
+	}
+	if alias == "" {
+		f.anonymous++
+		alias = fmt.Sprintf("@..edge%d", f.anonymous)
+	}
+	f.edges = append(f.edges, scsEdge{alias: alias, t: c.t, src: src, trg: trg, attributes: append([]string(nil), attributes...)})
+	return nil
+}
+
+// create resolves keynodes with one request and creates the rest with one construction
+func (f *scsFixture) create(client *sc.ScClient) (map[string]sc.ScAddr, error) {
+	addrs := make(map[string]sc.ScAddr)
+
+	var requests []sc.KeynodeRequest
+	for _, name := range f.names {
+		if strings.HasPrefix(name, "#") {
+			value, err := strconv.ParseInt(name[1:], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address %q", name)
+			}
+			addrs[name] = sc.ScAddr{Value: value}
+			continue
+		}
+		if _, isLink := f.links[name]; isLink || strings.HasPrefix(name, "..") {
+			continue
+		}
+
+		t := f.types[name]
+		if t == 0 {
+			t = sc.ScTypeNodeConst
+		}
+		requests = append(requests, sc.KeynodeRequest{Idtf: name, Type: sc.ScType{Value: t}})
+	}
+
+	keynodes, err := client.ResolveKeynodesOrdered(requests)
+	if err != nil {
+		return nil, err
+	}
+	for _, keynode := range keynodes {
+		if !keynode.Found {
+			return nil, fmt.Errorf("failed to resolve keynode %q", keynode.Idtf)
+		}
+		addrs[keynode.Idtf] = keynode.Addr
+	}
+
+	construction := &sc.ScConstruction{}
+	var created []string
+	ref := func(name string) interface{} {
+		if addr, exists := addrs[name]; exists {
+			return addr
+		}
+		return name
+	}
+
+	for _, name := range f.names {
+		content, isLink := f.links[name]
+		if !isLink && !strings.HasPrefix(name, "..") {
+			continue
+		}
+		if isLink {
+			err = construction.CreateLink(sc.ScType{Value: sc.ScTypeLinkConst}, sc.ScLinkContent{Data: content, Type: sc.ScLinkContentString}, name)
+		} else {
+			t := f.types[name]
+			if t == 0 || t&sc.ScTypeLink != 0 {
+				t = sc.ScTypeNodeConst
+			}
+			err = construction.CreateNode(sc.ScType{Value: t}, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, name)
+	}
+
+	for _, edge := range f.edges {
+		for _, end := range []string{edge.src, edge.trg} {
+			if _, defined := construction.GetIndex(end); strings.HasPrefix(end, "@") && !defined {
+				return nil, fmt.Errorf("edge %q is used before definition", end)
+			}
+		}
+		if err := construction.CreateEdge(sc.ScType{Value: edge.t}, ref(edge.src), ref(edge.trg), edge.alias); err != nil {
+			return nil, err
+		}
+		created = append(created, edge.alias)
+		for _, attribute := range edge.attributes {
+			if err := construction.CreateEdge(sc.ScType{Value: sc.ScTypeArcPosConstPerm}, ref(attribute), edge.alias, ""); err != nil {
+				return nil, err
+			}
+			created = append(created, "")
+		}
+	}
+
+	if len(construction.Commands) == 0 {
+		return addrs, nil
+	}
+	result, err := client.CreateElements(construction)
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range created {
+		if name != "" && !strings.HasPrefix(name, "@..") && i < len(result) {
+			addrs[name] = result[i]
+		}
+	}
+	return addrs, nil
+}
@@ -0,0 +1,153 @@
+package agenttest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	sc "github.com/temapriemnik/go-sc-client"
+)
+
+func TestSplitSentences(t *testing.T) {
+	sentences := splitSentences(`
+		// comment ;; is skipped
+		a -> b;;
+		c = [text ;; with // separators];;
+		d -> e`)
+	expected := []string{"a -> b", "c = [text ;; with // separators]", "d -> e"}
+	if !reflect.DeepEqual(sentences, expected) {
+		t.Errorf("expected %q, got %q", expected, sentences)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tokens, err := tokenize(`@e = (..a <=> #12);; x -/> rrel_1: [some text]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"@e", "=", "(", "..a", "<=>", "#12", ")", ";", ";", "x", "-/>", "rrel_1", ":", "[some text]"}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("expected %q, got %q", expected, tokens)
+	}
+
+	if _, err := tokenize(`a = [unclosed`); err == nil {
+		t.Error("expected error for unclosed link content")
+	}
+}
+
+func TestParseSentences(t *testing.T) {
+	f := &scsFixture{types: make(map[string]int), links: make(map[string]string)}
+	for _, sentence := range []string{
+		`a <- sc_node_class`,
+		`a => nrel_x: ..b; [text]; rrel_1: nrel_y: ..c`,
+		`@e = (..b <- a)`,
+		`name = [content]`,
+	} {
+		if err := f.parse(sentence); err != nil {
+			t.Fatalf("failed to parse %q: %v", sentence, err)
+		}
+	}
+
+	if f.types["a"] != sc.ScTypeNodeConstClass {
+		t.Errorf("unexpected type of a: %d", f.types["a"])
+	}
+	if f.links["name"] != "content" || len(f.links) != 2 || f.links[f.edges[1].trg] != "text" {
+		t.Errorf("unexpected links %v", f.links)
+	}
+
+	if len(f.edges) != 4 {
+		t.Fatalf("expected 4 edges, got %+v", f.edges)
+	}
+	last := f.edges[2]
+	if last.src != "a" || last.trg != "..c" || !reflect.DeepEqual(last.attributes, []string{"rrel_1", "nrel_y"}) {
+		t.Errorf("attributes are not reset by ';': %+v", last)
+	}
+	reversed := f.edges[3]
+	if reversed.alias != "@e" || reversed.src != "a" || reversed.trg != "..b" || reversed.t != sc.ScTypeArcPosConstPerm {
+		t.Errorf("unexpected reversed edge %+v", reversed)
+	}
+}
+
+func TestParseReversedConnectors(t *testing.T) {
+	for connector, reversed := range map[string]string{"<-": "->", "<|-": "-|>", "</-": "-/>", "<~": "~>", "<|~": "~|>", "</~": "~/>", "<=": "=>"} {
+		f := &scsFixture{types: make(map[string]int), links: make(map[string]string)}
+		if err := f.parse("a " + connector + " b"); err != nil {
+			t.Fatalf("failed to parse %q: %v", connector, err)
+		}
+		edge := f.edges[0]
+		if edge.src != "b" || edge.trg != "a" || edge.t != scsConnectors[reversed].t {
+			t.Errorf("unexpected edge of %q: %+v", connector, edge)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for sentence, message := range map[string]string{
+		`a ->`:           "too short",
+		`a ?> b`:         "unknown connector",
+		`@e = a -> b`:    "edge definition",
+		`a -> [x]: b`:    "should be node",
+		`a -> b c`:       "",
+		`a -> (`:         "element expected",
+		`@e = (a -> b))`: "edge definition",
+	} {
+		f := &scsFixture{types: make(map[string]int), links: make(map[string]string)}
+		err := f.parse(sentence)
+		if message == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %v", sentence, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("%q: expected error containing %q, got %v", sentence, message, err)
+		}
+	}
+}
+
+func TestLoadSCs(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := sc.NewScClient(server.URL())
+	defer client.Close()
+
+	addrs, err := LoadSCs(client, `
+		concept_fixture <- sc_node_class;;
+		..text = [fixture text];;
+		@member = (concept_fixture -> ..text);;
+		nrel_fixture -> @member;;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"concept_fixture", "..text", "@member", "nrel_fixture"} {
+		if !addrs[name].IsValid() {
+			t.Errorf("address of %q is not returned", name)
+		}
+	}
+
+	types, err := client.CheckElements([]sc.ScAddr{addrs["concept_fixture"], addrs["..text"], addrs["@member"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types[0].Value != sc.ScTypeNodeConstClass || types[1].Value != sc.ScTypeLinkConst || types[2].Value != sc.ScTypeArcPosConstPerm {
+		t.Errorf("unexpected types %v", types)
+	}
+	contents, err := client.GetLinkContents([]sc.ScAddr{addrs["..text"]})
+	if err != nil || contents[0].Data != "fixture text" {
+		t.Errorf("unexpected link contents %v: %v", contents, err)
+	}
+
+	// Keynodes are found again by next fixture
+	again, err := LoadSCs(client, `concept_fixture -> ..other;;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !again["concept_fixture"].Equal(addrs["concept_fixture"]) {
+		t.Error("keynode is created twice")
+	}
+
+	if _, err := LoadSCs(client, `@late -> a;; @late = (a -> b);;`); err == nil {
+		t.Error("expected error for edge used before definition")
+	}
+}
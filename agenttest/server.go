@@ -0,0 +1,466 @@
+package agenttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	sc "github.com/temapriemnik/go-sc-client"
+)
+
+// Server is in-process stand-in of sc-server keeping knowledge base in memory.
+// It implements commands used by sc.ScClient
+type Server struct {
+	store    *store
+	http     *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu            sync.Mutex
+	connections   map[*connection]bool
+	subscriptions map[int]*subscription
+	nextEventID   int
+}
+
+// connection represents connected client. Writes are serialized
+type connection struct {
+	ws *websocket.Conn
+	mu sync.Mutex
+}
+
+// subscription represents event created by client
+type subscription struct {
+	conn      *connection
+	addr      int64
+	eventType string
+}
+
+// delivery represents event message waiting to be sent
+type delivery struct {
+	conn    *connection
+	message sc.Response
+}
+
+// eventTypeNames maps names of events accepted by server to names used by store
+var eventTypeNames = map[string]string{
+	eventAddOutgoingEdge:    eventAddOutgoingEdge,
+	eventAddIngoingEdge:     eventAddIngoingEdge,
+	eventRemoveOutgoingEdge: eventRemoveOutgoingEdge,
+	eventRemoveIngoingEdge:  eventRemoveIngoingEdge,
+	eventRemoveElement:      eventRemoveElement,
+	eventChangeContent:      eventChangeContent,
+
+	string(sc.ScEventAfterGenerateOutgoingArc): eventAddOutgoingEdge,
+	string(sc.ScEventAfterGenerateIncomingArc): eventAddIngoingEdge,
+	string(sc.ScEventBeforeEraseOutgoingArc):   eventRemoveOutgoingEdge,
+	string(sc.ScEventBeforeEraseIncomingArc):   eventRemoveIngoingEdge,
+	string(sc.ScEventBeforeEraseElement):       eventRemoveElement,
+	string(sc.ScEventBeforeChangeLinkContent):  eventChangeContent,
+}
+
+// NewServer starts server on random local port
+func NewServer() *Server {
+	s := &Server{
+		store:         newStore(),
+		connections:   make(map[*connection]bool),
+		subscriptions: make(map[int]*subscription),
+	}
+	s.http = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// URL returns websocket URL of server
+func (s *Server) URL() string {
+	return "ws" + strings.TrimPrefix(s.http.URL, "http")
+}
+
+// Close closes client connections and stops server
+func (s *Server) Close() {
+	s.mu.Lock()
+	for conn := range s.connections {
+		conn.ws.Close()
+	}
+	s.mu.Unlock()
+	s.http.Close()
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade connection: %v", err)
+		return
+	}
+
+	conn := &connection{ws: ws}
+	s.mu.Lock()
+	s.connections[conn] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.connections, conn)
+		for id, sub := range s.subscriptions {
+			if sub.conn == conn {
+				delete(s.subscriptions, id)
+			}
+		}
+		s.mu.Unlock()
+		ws.Close()
+	}()
+
+	for {
+		_, message, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var request sc.Request
+		if err := json.Unmarshal(message, &request); err != nil {
+			log.Printf("Failed to unmarshal request: %v", err)
+			continue
+		}
+
+		payload, notifications, err := s.handle(conn, request)
+		response := sc.Response{ID: request.ID, Status: err == nil, Payload: payload}
+		if err != nil {
+			log.Printf("Request %s failed: %v", request.Type, err)
+		}
+		conn.write(response)
+
+		// Events are sent after response like sc-server does
+		s.notify(notifications)
+	}
+}
+
+// notify sends event messages of subscriptions matching notifications
+func (s *Server) notify(notifications []notification) {
+	for _, d := range s.deliveries(notifications) {
+		d.conn.write(d.message)
+	}
+}
+
+func (c *connection) write(message sc.Response) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Failed to marshal response: %v", err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.ws.WriteMessage(websocket.TextMessage, data); err != nil {
+		log.Printf("Write error: %v", err)
+	}
+}
+
+// deliveries returns event messages of subscriptions matching notifications
+func (s *Server) deliveries(notifications []notification) []delivery {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []delivery
+	for _, n := range notifications {
+		for id, sub := range s.subscriptions {
+			if sub.addr != n.addr || sub.eventType != n.eventType {
+				continue
+			}
+			result = append(result, delivery{
+				conn: sub.conn,
+				message: sc.Response{
+					ID:      id,
+					Status:  true,
+					Event:   true,
+					Payload: []int64{n.addr, n.edge, n.other},
+				},
+			})
+		}
+	}
+	return result
+}
+
+// handle executes request and returns response payload with raised events
+func (s *Server) handle(conn *connection, request sc.Request) (interface{}, []notification, error) {
+	if request.Type == "events" {
+		payload, err := s.handleEvents(conn, request.Payload)
+		return payload, nil, err
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	switch request.Type {
+	case "check_elements":
+		items, _ := request.Payload.([]interface{})
+		types := make([]int, len(items))
+		for i, item := range items {
+			types[i] = s.store.typeOf(toInt64(item))
+		}
+		return types, nil, nil
+	case "create_elements":
+		return s.createElements(request.Payload)
+	case "delete_elements":
+		// Events of removal are sent before elements are erased like sc-server does
+		items, _ := request.Payload.([]interface{})
+		for _, item := range items {
+			s.notify(s.store.erasing(toInt64(item)))
+			s.store.delete(toInt64(item))
+		}
+		return nil, nil, nil
+	case "content":
+		return s.content(request.Payload)
+	case "keynodes":
+		return s.keynodes(request.Payload), nil, nil
+	case "search_template":
+		return s.searchTemplate(request.Payload)
+	case "generate_template":
+		return s.generateTemplate(request.Payload)
+	default:
+		return nil, nil, fmt.Errorf("unknown request type %q", request.Type)
+	}
+}
+
+func (s *Server) createElements(payload interface{}) (interface{}, []notification, error) {
+	items, _ := payload.([]interface{})
+	addrs := make([]int64, len(items))
+
+	var notifications []notification
+	for i, item := range items {
+		cmd, _ := item.(map[string]interface{})
+		t := int(toInt64(cmd["type"]))
+
+		var err error
+		switch cmd["el"] {
+		case "node":
+			addrs[i], err = s.store.createNode(t)
+		case "link":
+			addrs[i], err = s.store.createLink(t, cmd["content"], contentTypeName(toInt64(cmd["content_type"])))
+		case "edge":
+			src, err := constructionRef(cmd["src"], addrs[:i])
+			if err != nil {
+				return nil, nil, err
+			}
+			trg, err := constructionRef(cmd["trg"], addrs[:i])
+			if err != nil {
+				return nil, nil, err
+			}
+			edge, created, err := s.store.createEdge(t, src, trg)
+			if err != nil {
+				return nil, nil, err
+			}
+			addrs[i] = edge
+			notifications = append(notifications, created...)
+		default:
+			return nil, nil, fmt.Errorf("unknown element %v", cmd["el"])
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return addrs, notifications, nil
+}
+
+// constructionRef returns address of edge end passed as addr or index of created element
+func constructionRef(value interface{}, created []int64) (int64, error) {
+	ref, _ := value.(map[string]interface{})
+	switch ref["type"] {
+	case "addr":
+		return toInt64(ref["value"]), nil
+	case "ref":
+		idx := int(toInt64(ref["value"]))
+		if idx < 0 || idx >= len(created) {
+			return 0, fmt.Errorf("invalid reference %d", idx)
+		}
+		return created[idx], nil
+	default:
+		return 0, fmt.Errorf("invalid edge end %v", value)
+	}
+}
+
+// contentTypeName converts sc.ScLinkContentType to name used in content requests
+func contentTypeName(t int64) string {
+	return sc.ScLinkContent{Type: sc.ScLinkContentType(t)}.TypeToStr()
+}
+
+func (s *Server) content(payload interface{}) (interface{}, []notification, error) {
+	items, _ := payload.([]interface{})
+	results := make([]interface{}, len(items))
+
+	var notifications []notification
+	for i, item := range items {
+		cmd, _ := item.(map[string]interface{})
+		switch cmd["command"] {
+		case "set":
+			contentType, _ := cmd["type"].(string)
+			ok, changed := s.store.setContent(toInt64(cmd["addr"]), cmd["data"], contentType)
+			results[i] = ok
+			notifications = append(notifications, changed...)
+		case "get":
+			content := s.store.content(toInt64(cmd["addr"]))
+			if content == nil {
+				results[i] = map[string]interface{}{"value": nil}
+				continue
+			}
+			results[i] = map[string]interface{}{"value": content.Data, "type": content.TypeToStr()}
+		case "find":
+			links := s.store.findLinks(cmd["data"])
+			if links == nil {
+				links = []int64{}
+			}
+			results[i] = links
+		default:
+			return nil, nil, fmt.Errorf("unknown content command %v", cmd["command"])
+		}
+	}
+	return results, notifications, nil
+}
+
+func (s *Server) keynodes(payload interface{}) interface{} {
+	items, _ := payload.([]interface{})
+	addrs := make([]int64, len(items))
+	for i, item := range items {
+		cmd, _ := item.(map[string]interface{})
+		idtf, _ := cmd["idtf"].(string)
+		if cmd["command"] == "resolve" {
+			addrs[i] = s.store.resolveKeynode(idtf, int(toInt64(cmd["elType"])))
+		} else {
+			addrs[i] = s.store.findKeynode(idtf)
+		}
+	}
+	return addrs
+}
+
+// parseTemplate parses template payload optionally wrapped with params
+func parseTemplate(payload interface{}) (*sc.ScTemplate, map[string]sc.ScAddr, error) {
+	params := make(map[string]sc.ScAddr)
+	raw := payload
+	if wrapped, ok := payload.(map[string]interface{}); ok {
+		raw = wrapped["templ"]
+		values, _ := wrapped["params"].(map[string]interface{})
+		for alias, value := range values {
+			params[alias] = sc.ScAddr{Value: toInt64(value)}
+		}
+	}
+
+	items, _ := raw.([]interface{})
+	template := &sc.ScTemplate{Triples: make([]sc.ScTemplateTriple, len(items))}
+	for i, item := range items {
+		values, _ := item.([]interface{})
+		if len(values) != 3 {
+			return nil, nil, fmt.Errorf("triple %d should have 3 items", i)
+		}
+
+		var parsed [3]sc.ScTemplateValue
+		for j, value := range values {
+			v, _ := value.(map[string]interface{})
+			parsed[j].Alias, _ = v["alias"].(string)
+			switch v["type"] {
+			case "addr":
+				parsed[j].Value = sc.ScAddr{Value: toInt64(v["value"])}
+			case "type":
+				parsed[j].Value = sc.ScType{Value: int(toInt64(v["value"]))}
+			case "alias":
+				ref, _ := v["value"].(string)
+				parsed[j].Value = ref
+			default:
+				return nil, nil, fmt.Errorf("invalid item %v of triple %d", v, i)
+			}
+		}
+		template.Triples[i] = sc.ScTemplateTriple{Source: parsed[0], Edge: parsed[1], Target: parsed[2]}
+	}
+	return template, params, nil
+}
+
+func (s *Server) searchTemplate(payload interface{}) (interface{}, []notification, error) {
+	template, params, err := parseTemplate(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	set, err := s.store.search(template, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows := make([][]int64, set.Len())
+	for i := range rows {
+		row := set.Row(i)
+		rows[i] = make([]int64, len(row.Addrs))
+		for j, addr := range row.Addrs {
+			rows[i][j] = addr.Value
+		}
+	}
+	return map[string]interface{}{
+		"aliases": set.Aliases,
+		"addrs":   rows,
+	}, nil, nil
+}
+
+func (s *Server) generateTemplate(payload interface{}) (interface{}, []notification, error) {
+	template, params, err := parseTemplate(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addrs, notifications, err := s.store.generate(template, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return map[string]interface{}{
+		"aliases": templateAliases(template),
+		"addrs":   addrs,
+	}, notifications, nil
+}
+
+func (s *Server) handleEvents(conn *connection, payload interface{}) (interface{}, error) {
+	request, _ := payload.(map[string]interface{})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if items, ok := request["create"].([]interface{}); ok {
+		subscriptions := make([]*subscription, len(items))
+		for i, item := range items {
+			params, _ := item.(map[string]interface{})
+			name, _ := params["type"].(string)
+			eventType, known := eventTypeNames[name]
+			if !known {
+				return nil, fmt.Errorf("unknown event type %q", name)
+			}
+			subscriptions[i] = &subscription{conn: conn, addr: toInt64(params["addr"]), eventType: eventType}
+		}
+
+		ids := make([]int, len(subscriptions))
+		for i, sub := range subscriptions {
+			s.nextEventID++
+			s.subscriptions[s.nextEventID] = sub
+			ids[i] = s.nextEventID
+		}
+		return ids, nil
+	}
+
+	items, _ := request["delete"].([]interface{})
+	for _, item := range items {
+		delete(s.subscriptions, int(toInt64(item)))
+	}
+	return nil, nil
+}
+
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,261 @@
+package agenttest
+
+import (
+	"testing"
+
+	"github.com/gorilla/websocket"
+	sc "github.com/temapriemnik/go-sc-client"
+)
+
+// rawClient sends requests to server without sc.ScClient
+type rawClient struct {
+	tb     testing.TB
+	ws     *websocket.Conn
+	nextID int
+}
+
+func dialServer(tb testing.TB) *rawClient {
+	server := NewServer()
+	tb.Cleanup(server.Close)
+
+	ws, _, err := websocket.DefaultDialer.Dial(server.URL(), nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { ws.Close() })
+	return &rawClient{tb: tb, ws: ws}
+}
+
+// request sends request and returns its response with events received before it
+func (c *rawClient) request(requestType string, payload interface{}) (sc.Response, []sc.Response) {
+	c.tb.Helper()
+
+	c.nextID++
+	if err := c.ws.WriteJSON(sc.Request{ID: c.nextID, Type: requestType, Payload: payload}); err != nil {
+		c.tb.Fatal(err)
+	}
+	var events []sc.Response
+	for {
+		var response sc.Response
+		if err := c.ws.ReadJSON(&response); err != nil {
+			c.tb.Fatal(err)
+		}
+		if response.Event {
+			events = append(events, response)
+			continue
+		}
+		if response.ID != c.nextID {
+			c.tb.Fatalf("expected response to %d, got %d", c.nextID, response.ID)
+		}
+		return response, events
+	}
+}
+
+// events reads event messages sent after the last response
+func (c *rawClient) events(count int) []sc.Response {
+	c.tb.Helper()
+
+	events := make([]sc.Response, count)
+	for i := range events {
+		if err := c.ws.ReadJSON(&events[i]); err != nil {
+			c.tb.Fatal(err)
+		}
+		if !events[i].Event {
+			c.tb.Fatalf("expected event, got %+v", events[i])
+		}
+	}
+	return events
+}
+
+func responseAddrs(tb testing.TB, response sc.Response) []int64 {
+	tb.Helper()
+
+	items, ok := response.Payload.([]interface{})
+	if !response.Status || !ok {
+		tb.Fatalf("unexpected response %+v", response)
+	}
+	result := make([]int64, len(items))
+	for i, item := range items {
+		result[i] = toInt64(item)
+	}
+	return result
+}
+
+func TestServerCreatesAndDeletesElements(t *testing.T) {
+	client := dialServer(t)
+
+	created, _ := client.request("create_elements", []interface{}{
+		map[string]interface{}{"el": "node", "type": sc.ScTypeNodeConst},
+		map[string]interface{}{"el": "link", "type": sc.ScTypeLinkConst, "content": "text", "content_type": int(sc.ScLinkContentString)},
+		map[string]interface{}{
+			"el":   "edge",
+			"type": sc.ScTypeArcPosConstPerm,
+			"src":  map[string]interface{}{"type": "ref", "value": 0},
+			"trg":  map[string]interface{}{"type": "ref", "value": 1},
+		},
+	})
+	elements := responseAddrs(t, created)
+	node, link, edge := elements[0], elements[1], elements[2]
+
+	subscribed, _ := client.request("events", map[string]interface{}{"create": []interface{}{
+		map[string]interface{}{"type": string(sc.ScEventBeforeEraseOutgoingArc), "addr": node},
+		map[string]interface{}{"type": eventRemoveElement, "addr": link},
+	}})
+	ids := responseAddrs(t, subscribed)
+
+	checked, _ := client.request("check_elements", []interface{}{node, link, edge, 1000})
+	if types := responseAddrs(t, checked); types[0] != sc.ScTypeNodeConst || types[1] != sc.ScTypeLinkConst || types[2] != sc.ScTypeArcPosConstPerm || types[3] != 0 {
+		t.Errorf("unexpected types %v", types)
+	}
+
+	// Deleting link deletes incident edge, events are sent before erasing and response
+	deleted, events := client.request("delete_elements", []interface{}{link})
+	if !deleted.Status || len(events) != 2 {
+		t.Fatalf("unexpected response %+v and events %v", deleted, events)
+	}
+	received := map[int64][]int64{}
+	for _, event := range events {
+		received[int64(event.ID)] = responseAddrs(t, event)
+	}
+	if payload := received[ids[0]]; len(payload) != 3 || payload[0] != node || payload[1] != edge || payload[2] != link {
+		t.Errorf("unexpected edge removal event %v", payload)
+	}
+	if payload := received[ids[1]]; len(payload) != 3 || payload[0] != link {
+		t.Errorf("unexpected element removal event %v", payload)
+	}
+
+	checked, _ = client.request("check_elements", []interface{}{node, link, edge})
+	if types := responseAddrs(t, checked); types[0] != sc.ScTypeNodeConst || types[1] != 0 || types[2] != 0 {
+		t.Errorf("unexpected types after deletion %v", types)
+	}
+
+	// Destroyed events are not sent
+	client.request("events", map[string]interface{}{"delete": []interface{}{ids[0]}})
+	_, events = client.request("delete_elements", []interface{}{node})
+	if len(events) != 0 {
+		t.Errorf("destroyed event is sent: %v", events)
+	}
+}
+
+func TestServerRejectsUnknownRequests(t *testing.T) {
+	client := dialServer(t)
+
+	if response, _ := client.request("unknown", nil); response.Status {
+		t.Error("unknown request succeeded")
+	}
+	if response, _ := client.request("events", map[string]interface{}{"create": []interface{}{
+		map[string]interface{}{"type": "unknown_event", "addr": 1},
+	}}); response.Status {
+		t.Error("unknown event type is accepted")
+	}
+	if response, _ := client.request("create_elements", []interface{}{
+		map[string]interface{}{"el": "edge", "type": sc.ScTypeArcPosConstPerm,
+			"src": map[string]interface{}{"type": "ref", "value": 5},
+			"trg": map[string]interface{}{"type": "addr", "value": 1}},
+	}); response.Status {
+		t.Error("edge with invalid reference is created")
+	}
+}
+
+func TestServerContentAndKeynodes(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := sc.NewScClient(server.URL())
+	defer client.Close()
+
+	keynodes, err := client.ResolveKeynodesOrdered([]sc.KeynodeRequest{
+		{Idtf: "missing_keynode"},
+		{Idtf: "created_keynode", Type: sc.ScType{Value: sc.ScTypeNodeConstClass}},
+		{Idtf: sc.KeynodeNrelSystemIdentifier},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keynodes[0].Found || !keynodes[1].Found || !keynodes[2].Found {
+		t.Fatalf("unexpected keynodes %+v", keynodes)
+	}
+	found, err := client.ResolveKeynodesOrdered([]sc.KeynodeRequest{{Idtf: "created_keynode"}})
+	if err != nil || !found[0].Addr.Equal(keynodes[1].Addr) {
+		t.Errorf("created keynode is not found: %+v, %v", found, err)
+	}
+
+	construction := &sc.ScConstruction{}
+	if err := construction.CreateLink(sc.ScType{Value: sc.ScTypeLinkConst}, sc.ScLinkContent{Data: "before", Type: sc.ScLinkContentString}, "link"); err != nil {
+		t.Fatal(err)
+	}
+	created, err := client.CreateElements(construction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := created[0]
+
+	updated, err := client.SetLinkContents([]sc.ScLinkContent{{Data: 42, Type: sc.ScLinkContentInt, Addr: &link}})
+	if err != nil || !updated[0] {
+		t.Fatalf("failed to set content: %v, %v", updated, err)
+	}
+	contents, err := client.GetLinkContents([]sc.ScAddr{link})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contents[0].Type != sc.ScLinkContentInt || contents[0].Data != float64(42) {
+		t.Errorf("unexpected content %+v", contents[0])
+	}
+	links, err := client.FindLinksByContents([]interface{}{42, "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links[0]) != 1 || !links[0][0].Equal(link) || len(links[1]) != 0 {
+		t.Errorf("unexpected links %v", links)
+	}
+}
+
+func TestServerSearchesAndGeneratesTemplates(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := sc.NewScClient(server.URL())
+	defer client.Close()
+
+	fixture, err := LoadSCs(client, `
+		concept_city <- sc_node_class;;
+		concept_city -> ..minsk; ..paris;;
+		..minsk => nrel_country: ..belarus;;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &sc.ScTemplate{}
+	template.Triple(fixture["concept_city"], sc.ScType{Value: sc.ScTypeArcPosVarPerm}, []interface{}{sc.ScType{Value: sc.ScTypeNodeVar}, "_city"})
+	results, err := client.TemplateSearch(template)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("expected 2 cities, got %v: %v", results, err)
+	}
+
+	// Relation triple is bound through alias of edge
+	template.TripleWithRelation("_city", sc.ScType{Value: sc.ScTypeDEdgeVar}, []interface{}{sc.ScType{Value: sc.ScTypeNodeVar}, "_country"},
+		sc.ScType{Value: sc.ScTypeArcPosVarPerm}, fixture["nrel_country"])
+	results, err = client.TemplateSearch(template)
+	if err != nil || len(results) != 1 || !results[0].Get("_city").Equal(fixture["..minsk"]) || !results[0].Get("_country").Equal(fixture["..belarus"]) {
+		t.Fatalf("unexpected relation results %v: %v", results, err)
+	}
+
+	results, err = client.TemplateSearchWithParams(template, map[string]sc.ScAddr{"_city": fixture["..paris"]})
+	if err != nil || len(results) != 0 {
+		t.Errorf("params are not applied: %v, %v", results, err)
+	}
+
+	relation := &sc.ScTemplate{}
+	relation.TripleWithRelation(fixture["..paris"], sc.ScType{Value: sc.ScTypeDEdgeVar}, []interface{}{sc.ScType{Value: sc.ScTypeNodeVar}, "_country"},
+		sc.ScType{Value: sc.ScTypeArcPosVarPerm}, fixture["nrel_country"])
+	generated, err := client.TemplateGenerate(relation, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !generated.Get("_country").IsValid() {
+		t.Errorf("unexpected generated result %v", generated)
+	}
+	results, err = client.TemplateSearch(template)
+	if err != nil || len(results) != 2 {
+		t.Errorf("generated construction is not found: %v, %v", results, err)
+	}
+}
@@ -0,0 +1,293 @@
+package agenttest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	sc "github.com/temapriemnik/go-sc-client"
+)
+
+// Legacy names of events emitted by store. Modern names are translated to them by server
+const (
+	eventAddOutgoingEdge    = "add_outgoing_edge"
+	eventAddIngoingEdge     = "add_ingoing_edge"
+	eventRemoveOutgoingEdge = "remove_outgoing_edge"
+	eventRemoveIngoingEdge  = "remove_ingoing_edge"
+	eventRemoveElement      = "delete_element"
+	eventChangeContent      = "content_change"
+)
+
+// notification represents event raised on element by change of knowledge base
+type notification struct {
+	addr      int64
+	eventType string
+	edge      int64
+	other     int64
+}
+
+// store is in-memory knowledge base kept in sc.Graph, so templates are searched by its matcher.
+// Elements are never reused after deletion
+type store struct {
+	mu      sync.Mutex
+	next    int64
+	graph   *sc.Graph
+	sysIdtf int64
+}
+
+func newStore() *store {
+	s := &store{graph: sc.NewGraph(sc.ScAddr{})}
+
+	// nrel_system_identifier identifies itself
+	s.sysIdtf, _ = s.createNode(sc.ScTypeNodeConstNoRole)
+	s.setIdtf(s.sysIdtf, sc.KeynodeNrelSystemIdentifier)
+	return s
+}
+
+func (s *store) createNode(t int) (int64, error) {
+	s.next++
+	if _, err := s.graph.AddElement(sc.ScAddr{Value: s.next}, sc.ScType{Value: t}); err != nil {
+		return 0, err
+	}
+	return s.next, nil
+}
+
+func (s *store) createLink(t int, content interface{}, contentType string) (int64, error) {
+	addr, err := s.createNode(t)
+	if err != nil {
+		return 0, err
+	}
+	if content != nil {
+		s.graph.Element(sc.ScAddr{Value: addr}).Content = &sc.ScLinkContent{Data: content, Type: sc.StringToType(contentType)}
+	}
+	return addr, nil
+}
+
+func (s *store) createEdge(t int, src, trg int64) (int64, []notification, error) {
+	if !(sc.ScType{Value: t}).IsEdge() {
+		return 0, nil, fmt.Errorf("type %d is not type of edge", t)
+	}
+
+	s.next++
+	if _, err := s.graph.AddEdge(sc.ScAddr{Value: s.next}, sc.ScType{Value: t}, sc.ScAddr{Value: src}, sc.ScAddr{Value: trg}); err != nil {
+		return 0, nil, err
+	}
+	return s.next, []notification{
+		{addr: src, eventType: eventAddOutgoingEdge, edge: s.next, other: trg},
+		{addr: trg, eventType: eventAddIngoingEdge, edge: s.next, other: src},
+	}, nil
+}
+
+// delete removes element with all incident edges
+func (s *store) delete(addr int64) []notification {
+	notifications := s.erasing(addr)
+	s.graph.Remove(sc.ScAddr{Value: addr})
+	return notifications
+}
+
+// erasing returns notifications of deleting element in order of removal without deleting it.
+// Edges incident to element are removed before it, like sc.Graph.Remove does
+func (s *store) erasing(addr int64) []notification {
+	var notifications []notification
+	visited := make(map[int64]bool)
+	var walk func(addr int64)
+	walk = func(addr int64) {
+		el := s.graph.Element(sc.ScAddr{Value: addr})
+		if el == nil || visited[addr] {
+			return
+		}
+		visited[addr] = true
+
+		for _, edge := range append(s.graph.Out(el.Addr), s.graph.In(el.Addr)...) {
+			walk(edge.Addr.Value)
+		}
+		if el.IsEdge() {
+			notifications = append(notifications,
+				notification{addr: el.Source.Value, eventType: eventRemoveOutgoingEdge, edge: el.Addr.Value, other: el.Target.Value},
+				notification{addr: el.Target.Value, eventType: eventRemoveIngoingEdge, edge: el.Addr.Value, other: el.Source.Value},
+			)
+		}
+		notifications = append(notifications, notification{addr: el.Addr.Value, eventType: eventRemoveElement})
+	}
+	walk(addr)
+	return notifications
+}
+
+func (s *store) typeOf(addr int64) int {
+	if el := s.graph.Element(sc.ScAddr{Value: addr}); el != nil {
+		return el.Type.Value
+	}
+	return 0
+}
+
+// content returns content of link, nil if it is missing
+func (s *store) content(addr int64) *sc.ScLinkContent {
+	if el := s.graph.Element(sc.ScAddr{Value: addr}); el != nil {
+		return el.Content
+	}
+	return nil
+}
+
+func (s *store) setContent(addr int64, content interface{}, contentType string) (bool, []notification) {
+	el := s.graph.Element(sc.ScAddr{Value: addr})
+	if el == nil || !el.Type.IsLink() {
+		return false, nil
+	}
+	el.Content = &sc.ScLinkContent{Data: content, Type: sc.StringToType(contentType)}
+	return true, []notification{{addr: addr, eventType: eventChangeContent}}
+}
+
+// findLinks returns links with content equal to data
+func (s *store) findLinks(data interface{}) []int64 {
+	var links []int64
+	for _, el := range s.graph.Nodes() {
+		if el.Type.IsLink() && el.Content != nil && fmt.Sprint(el.Content.Data) == fmt.Sprint(data) {
+			links = append(links, el.Addr.Value)
+		}
+	}
+	return links
+}
+
+// setIdtf creates addr => nrel_system_identifier: [idtf]
+func (s *store) setIdtf(addr int64, idtf string) {
+	link, _ := s.createLink(sc.ScTypeLinkConst, idtf, "string")
+	edge, _, _ := s.createEdge(sc.ScTypeDEdgeConst, addr, link)
+	s.createEdge(sc.ScTypeArcPosConstPerm, s.sysIdtf, edge)
+}
+
+// findKeynode returns element with system identifier, 0 if there is no one
+func (s *store) findKeynode(idtf string) int64 {
+	for _, link := range s.findLinks(idtf) {
+		for _, edge := range s.graph.In(sc.ScAddr{Value: link}) {
+			if edge.Type.Value&sc.ScTypeDEdgeCommon == 0 {
+				continue
+			}
+			for _, arc := range s.graph.In(edge.Addr) {
+				if arc.Source.Value == s.sysIdtf {
+					return edge.Source.Value
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// resolveKeynode finds element with system identifier or creates it when type is passed
+func (s *store) resolveKeynode(idtf string, t int) int64 {
+	if addr := s.findKeynode(idtf); addr != 0 || t == 0 {
+		return addr
+	}
+	addr, err := s.createNode(t)
+	if err != nil {
+		return 0
+	}
+	s.setIdtf(addr, idtf)
+	return addr
+}
+
+// templateAliases returns index of first item defining each alias
+func templateAliases(template *sc.ScTemplate) map[string]int {
+	aliases := make(map[string]int)
+	for i, triple := range template.Triples {
+		for j, item := range []sc.ScTemplateValue{triple.Source, triple.Edge, triple.Target} {
+			if _, exists := aliases[item.Alias]; item.Alias != "" && !exists {
+				aliases[item.Alias] = 3*i + j
+			}
+		}
+	}
+	return aliases
+}
+
+// bound returns address of item known before generation
+func bound(item sc.ScTemplateValue, binding map[string]sc.ScAddr) (sc.ScAddr, bool) {
+	switch v := item.Value.(type) {
+	case sc.ScAddr:
+		return v, true
+	case string:
+		addr, exists := binding[v]
+		return addr, exists
+	}
+	if item.Alias != "" {
+		addr, exists := binding[item.Alias]
+		return addr, exists
+	}
+	return sc.ScAddr{}, false
+}
+
+// constType returns constant type of element generated for template type
+func constType(item sc.ScTemplateValue) sc.ScType {
+	t, _ := item.Value.(sc.ScType)
+	if t.IsVar() {
+		return t.AsConst()
+	}
+	return t
+}
+
+// search returns results of template
+func (s *store) search(template *sc.ScTemplate, params map[string]sc.ScAddr) (*sc.ScTemplateResultSet, error) {
+	return s.graph.TemplateSearch(template, params)
+}
+
+// generate creates elements of template. Returns addresses of all triple items
+func (s *store) generate(template *sc.ScTemplate, params map[string]sc.ScAddr) ([]int64, []notification, error) {
+	binding := make(map[string]sc.ScAddr, len(params))
+	for alias, addr := range params {
+		binding[alias] = addr
+	}
+
+	resolve := func(item sc.ScTemplateValue) (int64, error) {
+		if addr, known := bound(item, binding); known {
+			return addr.Value, nil
+		}
+		if ref, isRef := item.Value.(string); isRef {
+			return 0, fmt.Errorf("alias %q is not defined", ref)
+		}
+
+		t := constType(item)
+		var addr int64
+		var err error
+		switch {
+		case t.IsLink():
+			addr, err = s.createLink(t.Value, nil, "")
+		case t.IsEdge():
+			return 0, errors.New("edge can not be generated as source or target")
+		default:
+			addr, err = s.createNode(t.Value | sc.ScTypeNode)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if item.Alias != "" {
+			binding[item.Alias] = sc.ScAddr{Value: addr}
+		}
+		return addr, nil
+	}
+
+	var notifications []notification
+	addrs := make([]int64, 0, 3*len(template.Triples))
+	for _, triple := range template.Triples {
+		src, err := resolve(triple.Source)
+		if err != nil {
+			return nil, nil, err
+		}
+		trg, err := resolve(triple.Target)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		edge, known := bound(triple.Edge, binding)
+		if !known {
+			var created []notification
+			edge.Value, created, err = s.createEdge(constType(triple.Edge).Value, src, trg)
+			if err != nil {
+				return nil, nil, err
+			}
+			notifications = append(notifications, created...)
+			if triple.Edge.Alias != "" {
+				binding[triple.Edge.Alias] = edge
+			}
+		}
+		addrs = append(addrs, src, edge.Value, trg)
+	}
+	return addrs, notifications, nil
+}
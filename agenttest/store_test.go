@@ -0,0 +1,80 @@
+package agenttest
+
+import (
+	"testing"
+
+	sc "github.com/temapriemnik/go-sc-client"
+)
+
+func TestStoreDeleteRemovesIncidentEdges(t *testing.T) {
+	s := newStore()
+	src, _ := s.createNode(sc.ScTypeNodeConst)
+	trg, _ := s.createNode(sc.ScTypeNodeConst)
+	relation, _ := s.createNode(sc.ScTypeNodeConstNoRole)
+	edge, created, err := s.createEdge(sc.ScTypeDEdgeConst, src, trg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 2 || created[0].eventType != eventAddOutgoingEdge || created[1].eventType != eventAddIngoingEdge {
+		t.Errorf("unexpected notifications of created edge %+v", created)
+	}
+	arc, _, err := s.createEdge(sc.ScTypeArcPosConstPerm, relation, edge)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Edge ending in edge is deleted before it
+	notifications := s.delete(trg)
+	var order []int64
+	for _, n := range notifications {
+		if n.eventType == eventRemoveElement {
+			order = append(order, n.addr)
+		}
+	}
+	if len(order) != 3 || order[0] != arc || order[1] != edge || order[2] != trg {
+		t.Errorf("unexpected deletion order %v", order)
+	}
+	if s.typeOf(edge) != 0 || s.typeOf(arc) != 0 || len(s.graph.Out(sc.ScAddr{Value: src})) != 0 || len(s.graph.Out(sc.ScAddr{Value: relation})) != 0 {
+		t.Error("incident edges are left")
+	}
+	if s.delete(trg) != nil {
+		t.Error("deleted element is deleted again")
+	}
+
+	if _, _, err := s.createEdge(sc.ScTypeArcPosConstPerm, src, trg); err == nil {
+		t.Error("edge to deleted element is created")
+	}
+}
+
+func TestStoreKeynodesAndContents(t *testing.T) {
+	s := newStore()
+	if s.findKeynode(sc.KeynodeNrelSystemIdentifier) != s.sysIdtf {
+		t.Error("nrel_system_identifier does not identify itself")
+	}
+
+	if s.resolveKeynode("concept_missing", 0) != 0 {
+		t.Error("keynode is created without type")
+	}
+	created := s.resolveKeynode("concept_created", sc.ScTypeNodeConstClass)
+	if created == 0 || s.typeOf(created) != sc.ScTypeNodeConstClass {
+		t.Fatalf("keynode is not created with type")
+	}
+	if s.resolveKeynode("concept_created", sc.ScTypeNodeConstClass) != created {
+		t.Error("existing keynode is created again")
+	}
+
+	link, _ := s.createLink(sc.ScTypeLinkConst, "concept_created", "string")
+	if links := s.findLinks("concept_created"); len(links) != 2 || links[1] != link {
+		t.Errorf("unexpected links %v", links)
+	}
+	if ok, _ := s.setContent(created, "text", "string"); ok {
+		t.Error("content of node is set")
+	}
+	ok, changed := s.setContent(link, 1.5, "float")
+	if !ok || len(changed) != 1 || changed[0].eventType != eventChangeContent {
+		t.Errorf("unexpected content change %v %+v", ok, changed)
+	}
+	if s.findKeynode("concept_created") != created {
+		t.Error("keynode is found by link which is not its identifier")
+	}
+}
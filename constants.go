@@ -21,8 +21,34 @@ const (
 	ScTypeNodeClass       = 0x800
 	ScTypeNodeAbstract    = 0x1000
 	ScTypeNodeMaterial    = 0x2000
+	ScTypeNodeConst       = ScTypeNode | ScTypeConst
+	ScTypeNodeVar         = ScTypeNode | ScTypeVar
+	ScTypeNodeConstClass  = ScTypeNode | ScTypeConst | ScTypeNodeClass
+	ScTypeNodeVarClass    = ScTypeNode | ScTypeVar | ScTypeNodeClass
+	ScTypeNodeConstRole   = ScTypeNode | ScTypeConst | ScTypeNodeRole
+	ScTypeNodeVarRole     = ScTypeNode | ScTypeVar | ScTypeNodeRole
+	ScTypeNodeConstNoRole = ScTypeNode | ScTypeConst | ScTypeNodeNoRole
+	ScTypeNodeVarNoRole   = ScTypeNode | ScTypeVar | ScTypeNodeNoRole
+	ScTypeNodeConstTuple  = ScTypeNode | ScTypeConst | ScTypeNodeTuple
+	ScTypeNodeVarTuple    = ScTypeNode | ScTypeVar | ScTypeNodeTuple
+	ScTypeNodeConstStruct = ScTypeNode | ScTypeConst | ScTypeNodeStruct
+	ScTypeNodeVarStruct   = ScTypeNode | ScTypeVar | ScTypeNodeStruct
+	ScTypeLinkConst       = ScTypeLink | ScTypeConst
+	ScTypeLinkVar         = ScTypeLink | ScTypeVar
+	ScTypeDEdgeConst      = ScTypeDEdgeCommon | ScTypeConst
+	ScTypeDEdgeVar        = ScTypeDEdgeCommon | ScTypeVar
 	ScTypeArcPosConstPerm = ScTypeEdgeAccess | ScTypeConst | ScTypeEdgePos | ScTypeEdgePerm
 	ScTypeArcPosVarPerm   = ScTypeEdgeAccess | ScTypeVar | ScTypeEdgePos | ScTypeEdgePerm
+	ScTypeArcPosConstTemp = ScTypeEdgeAccess | ScTypeConst | ScTypeEdgePos | ScTypeEdgeTemp
+	ScTypeArcPosVarTemp   = ScTypeEdgeAccess | ScTypeVar | ScTypeEdgePos | ScTypeEdgeTemp
+	ScTypeArcNegConstPerm = ScTypeEdgeAccess | ScTypeConst | ScTypeEdgeNeg | ScTypeEdgePerm
+	ScTypeArcNegVarPerm   = ScTypeEdgeAccess | ScTypeVar | ScTypeEdgeNeg | ScTypeEdgePerm
+	ScTypeArcNegConstTemp = ScTypeEdgeAccess | ScTypeConst | ScTypeEdgeNeg | ScTypeEdgeTemp
+	ScTypeArcNegVarTemp   = ScTypeEdgeAccess | ScTypeVar | ScTypeEdgeNeg | ScTypeEdgeTemp
+	ScTypeArcFuzConstPerm = ScTypeEdgeAccess | ScTypeConst | ScTypeEdgeFuz | ScTypeEdgePerm
+	ScTypeArcFuzVarPerm   = ScTypeEdgeAccess | ScTypeVar | ScTypeEdgeFuz | ScTypeEdgePerm
+	ScTypeArcFuzConstTemp = ScTypeEdgeAccess | ScTypeConst | ScTypeEdgeFuz | ScTypeEdgeTemp
+	ScTypeArcFuzVarTemp   = ScTypeEdgeAccess | ScTypeVar | ScTypeEdgeFuz | ScTypeEdgeTemp
 )
 
 // ScLinkContentType represents link content type
@@ -46,4 +72,22 @@ const (
 	ScEventRemoveIngoingEdge  ScEventType = "remove_ingoing_edge"
 	ScEventRemoveElement      ScEventType = "delete_element"
 	ScEventChangeContent      ScEventType = "content_change"
+
+	ScEventAfterGenerateOutgoingArc ScEventType = "sc_event_after_generate_outgoing_arc"
+	ScEventAfterGenerateIncomingArc ScEventType = "sc_event_after_generate_incoming_arc"
+	ScEventAfterGenerateEdge        ScEventType = "sc_event_after_generate_edge"
+	ScEventBeforeEraseOutgoingArc   ScEventType = "sc_event_before_erase_outgoing_arc"
+	ScEventBeforeEraseIncomingArc   ScEventType = "sc_event_before_erase_incoming_arc"
+	ScEventBeforeEraseEdge          ScEventType = "sc_event_before_erase_edge"
+	ScEventBeforeEraseElement       ScEventType = "sc_event_before_erase_element"
+	ScEventBeforeChangeLinkContent  ScEventType = "sc_event_before_change_link_content"
+)
+
+// ScEventVocabulary represents set of event type names understood by server
+type ScEventVocabulary int
+
+const (
+	ScEventVocabularyUnknown ScEventVocabulary = iota
+	ScEventVocabularyLegacy
+	ScEventVocabularyModern
 )
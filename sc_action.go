@@ -0,0 +1,286 @@
+package sc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ScActionStatus represents state an action was finished with
+type ScActionStatus int
+
+const (
+	ScActionFinished ScActionStatus = iota
+	ScActionFinishedSuccessfully
+	ScActionFinishedUnsuccessfully
+	ScActionFinishedWithError
+)
+
+func (s ScActionStatus) String() string {
+	switch s {
+	case ScActionFinishedSuccessfully:
+		return "action_finished_successfully"
+	case ScActionFinishedUnsuccessfully:
+		return "action_finished_unsuccessfully"
+	case ScActionFinishedWithError:
+		return "action_finished_with_error"
+	default:
+		return "action_finished"
+	}
+}
+
+// ScActionResult represents finished action
+type ScActionResult struct {
+	Action ScAddr
+	Status ScActionStatus
+	// Result is a structure connected with action by nrel_result, invalid addr if agent did not set it
+	Result ScAddr
+}
+
+// Succeeded returns true if action was finished successfully
+func (r *ScActionResult) Succeeded() bool {
+	return r.Status == ScActionFinishedSuccessfully
+}
+
+// InitiateAction creates action of class passed as ScAddr or system identifier with arguments
+// attached by rrel_1, rrel_2, ..., adds it to action_initiated and waits until it is finished.
+// Waiting is stopped when ctx is cancelled
+func (c *ScClient) InitiateAction(ctx context.Context, actionClass interface{}, args ...ScAddr) (*ScActionResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	keynodes, err := c.CommonKeynodes()
+	if err != nil {
+		return nil, err
+	}
+	classAddr, err := c.resolveElement(actionClass)
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := c.CreateAction(classAddr, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Listen before initiation, so agent is not able to finish action unnoticed.
+	// Status classes may be added before action_finished, they are read after it
+	finished := make(chan struct{}, 1)
+	listener, err := c.AddListener(action, ScEventAddIngoingEdge, func(elAddr, edge, other ScAddr, eventID int) {
+		select {
+		case finished <- struct{}{}:
+		default:
+		}
+	}, func(elAddr, edge, other ScAddr) bool {
+		return other.Equal(keynodes.ActionFinished)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+
+	construction := &ScConstruction{}
+	if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, keynodes.ActionInitiated, action, ""); err != nil {
+		return nil, err
+	}
+	if _, err := c.CreateElements(construction); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return c.actionResult(action, keynodes)
+}
+
+// CreateAction creates action of class with arguments attached by rrel_1, rrel_2, ... without initiating it
+func (c *ScClient) CreateAction(actionClass ScAddr, args ...ScAddr) (ScAddr, error) {
+	keynodes, err := c.CommonKeynodes()
+	if err != nil {
+		return ScAddr{}, err
+	}
+
+	roles := make(map[string]ScType, len(args))
+	for i := range args {
+		roles[fmt.Sprintf("rrel_%d", i+1)] = ScType{Value: ScTypeNodeConstRole}
+	}
+	roleAddrs, err := c.resolveCachedKeynodes(roles)
+	if err != nil {
+		return ScAddr{}, err
+	}
+
+	construction := &ScConstruction{}
+	if err := construction.CreateNode(ScType{Value: ScTypeNodeConst}, "action"); err != nil {
+		return ScAddr{}, err
+	}
+	if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, keynodes.Action, "action", ""); err != nil {
+		return ScAddr{}, err
+	}
+	if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, actionClass, "action", ""); err != nil {
+		return ScAddr{}, err
+	}
+	for i, arg := range args {
+		arcAlias := fmt.Sprintf("arg_%d", i+1)
+		if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, "action", arg, arcAlias); err != nil {
+			return ScAddr{}, err
+		}
+		role := roleAddrs[fmt.Sprintf("rrel_%d", i+1)]
+		if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, role, arcAlias, ""); err != nil {
+			return ScAddr{}, err
+		}
+	}
+
+	addrs, err := c.CreateElements(construction)
+	if err != nil {
+		return ScAddr{}, err
+	}
+	if len(addrs) == 0 || !addrs[0].IsValid() {
+		return ScAddr{}, errors.New("failed to create action")
+	}
+	return addrs[0], nil
+}
+
+// actionResult reads status and result structure of finished action
+func (c *ScClient) actionResult(action ScAddr, keynodes *CommonKeynodes) (*ScActionResult, error) {
+	statuses := []struct {
+		class  ScAddr
+		status ScActionStatus
+	}{
+		{keynodes.ActionFinishedSuccessfully, ScActionFinishedSuccessfully},
+		{keynodes.ActionFinishedUnsuccessfully, ScActionFinishedUnsuccessfully},
+		{keynodes.ActionFinishedWithError, ScActionFinishedWithError},
+	}
+
+	requests := make([]<-chan *ScTemplateResultSet, len(statuses))
+	for i, status := range statuses {
+		template := &ScTemplate{}
+		template.Triple(status.class, ScType{Value: ScTypeArcPosVarPerm}, action)
+		request, err := c.sendTemplateSearch(template, nil)
+		if err != nil {
+			return nil, err
+		}
+		requests[i] = request
+	}
+	resultRequest, err := c.sendTemplateSearch(relationTemplate(action, keynodes.NrelResult, []interface{}{ScType{}, "_result"}), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ScActionResult{Action: action, Status: ScActionFinished}
+	for i, request := range requests {
+		set, err := waitTemplateSearch(request)
+		if err != nil {
+			return nil, err
+		}
+		if set.Len() > 0 && result.Status == ScActionFinished {
+			result.Status = statuses[i].status
+		}
+	}
+
+	set, err := waitTemplateSearch(resultRequest)
+	if err != nil {
+		return nil, err
+	}
+	if set.Len() > 0 {
+		result.Result = set.Row(0).Get("_result")
+	}
+	return result, nil
+}
+
+// statusClass returns class action with status is added to besides action_finished
+func (s ScActionStatus) statusClass(keynodes *CommonKeynodes) ScAddr {
+	switch s {
+	case ScActionFinishedSuccessfully:
+		return keynodes.ActionFinishedSuccessfully
+	case ScActionFinishedUnsuccessfully:
+		return keynodes.ActionFinishedUnsuccessfully
+	case ScActionFinishedWithError:
+		return keynodes.ActionFinishedWithError
+	default:
+		return ScAddr{}
+	}
+}
+
+// ActionArguments returns arguments of action in order of rrel_1, rrel_2, ... roles.
+// Missing arguments are represented by invalid addrs
+func (c *ScClient) ActionArguments(action ScAddr) ([]ScAddr, error) {
+	template := &ScTemplate{}
+	template.TripleWithRelation(
+		action,
+		[]interface{}{ScType{Value: ScTypeArcPosVarPerm}, "_arc"},
+		[]interface{}{ScType{}, "_arg"},
+		[]interface{}{ScType{Value: ScTypeArcPosVarPerm}, "_role_arc"},
+		[]interface{}{ScType{Value: ScTypeNodeVarRole}, "_role"},
+	)
+
+	set, err := c.TemplateSearchSet(template)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := set.Distinct("_role")
+	if len(roles) == 0 {
+		return []ScAddr{}, nil
+	}
+	idtfs, err := c.GetSystemIdtfs(roles)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make(map[int64]int, len(roles))
+	count := 0
+	for i, role := range roles {
+		var index int
+		if _, err := fmt.Sscanf(idtfs[i], "rrel_%d", &index); err != nil || index <= 0 {
+			continue
+		}
+		indexes[role.Value] = index
+		if index > count {
+			count = index
+		}
+	}
+
+	args := make([]ScAddr, count)
+	set.ForEach(func(row ScTemplateResult) bool {
+		if index, isArg := indexes[row.Get("_role").Value]; isArg {
+			args[index-1] = row.Get("_arg")
+		}
+		return true
+	})
+	return args, nil
+}
+
+// FinishAction connects result structure with action by nrel_result and adds action
+// to action_finished and class of status. Result may be invalid addr
+func (c *ScClient) FinishAction(action ScAddr, status ScActionStatus, result ScAddr) error {
+	keynodes, err := c.CommonKeynodes()
+	if err != nil {
+		return err
+	}
+
+	construction := &ScConstruction{}
+	if result.IsValid() {
+		if err := construction.CreateEdge(ScType{Value: ScTypeDEdgeConst}, action, result, "result_edge"); err != nil {
+			return err
+		}
+		if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, keynodes.NrelResult, "result_edge", ""); err != nil {
+			return err
+		}
+	}
+	if class := status.statusClass(keynodes); class.IsValid() {
+		if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, class, action, ""); err != nil {
+			return err
+		}
+	}
+	// action_finished is added last, so waiters see status and result when they are notified
+	if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, keynodes.ActionFinished, action, ""); err != nil {
+		return err
+	}
+
+	_, err = c.CreateElements(construction)
+	return err
+}
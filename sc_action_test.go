@@ -0,0 +1,55 @@
+package sc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sc "github.com/temapriemnik/go-sc-client"
+	"github.com/temapriemnik/go-sc-client/agenttest"
+)
+
+func TestInitiateActionWaitsForActionFinished(t *testing.T) {
+	kit := agenttest.New(t)
+	keynodes, err := kit.Client.CommonKeynodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrs := kit.LoadSCs(`
+		action_slow <- sc_node_class;;
+		..result <- sc_node_struct;;
+	`)
+
+	// Status class is added long before action_finished
+	listener, err := kit.Client.AddListener(keynodes.ActionInitiated, sc.ScEventAddOutgoingEdge, func(elAddr, edge, action sc.ScAddr, eventID int) {
+		go func() {
+			construction := &sc.ScConstruction{}
+			if err := construction.CreateEdge(sc.ScType{Value: sc.ScTypeArcPosConstPerm}, keynodes.ActionFinishedSuccessfully, action, ""); err != nil {
+				t.Error(err)
+				return
+			}
+			if _, err := kit.Client.CreateElements(construction); err != nil {
+				t.Error(err)
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+			if err := kit.Client.FinishAction(action, sc.ScActionFinishedSuccessfully, addrs["..result"]); err != nil {
+				t.Error(err)
+			}
+		}()
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := kit.Client.InitiateAction(ctx, "action_slow")
+	if err != nil {
+		t.Fatalf("failed to initiate action: %v", err)
+	}
+	if !result.Succeeded() || !result.Result.Equal(addrs["..result"]) {
+		t.Errorf("action is reported before it is finished: %+v", result)
+	}
+}
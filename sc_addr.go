@@ -1,5 +1,7 @@
 package sc
 
+import "fmt"
+
 // ScAddr represents SC address
 type ScAddr struct {
 	Value int64
@@ -14,3 +16,8 @@ func (a ScAddr) IsValid() bool {
 func (a ScAddr) Equal(other ScAddr) bool {
 	return a.Value == other.Value
 }
+
+// String returns string representation of address
+func (a ScAddr) String() string {
+	return fmt.Sprintf("#%d", a.Value)
+}
@@ -3,6 +3,7 @@ package sc
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"sync"
 	"time"
@@ -29,12 +30,19 @@ type Request struct {
 type ScClient struct {
 	url          string
 	conn         *websocket.Conn
-	messageQueue []func()
+	messageQueue [][]byte
 	callbacks    map[int]func(Response)
 	events       map[int]*ScEvent
 	eventID      int
 	mu           sync.Mutex
 	done         chan struct{}
+	connected    bool
+
+	checkTemplates  bool
+	eventVocabulary ScEventVocabulary
+	listeners       listeners
+	deliveries      *eventDeliveries
+	keynodes        keynodeRegistry
 }
 
 // NewScClient creates new SC client
@@ -45,23 +53,28 @@ func NewScClient(url string) *ScClient {
 		events:    make(map[int]*ScEvent),
 		done:      make(chan struct{}),
 	}
+	client.deliveries = newEventDeliveries()
 
 	go client.connect()
+	go client.dispatchEvents()
 	return client
 }
 
 // Connect establishes connection to SC-machine
 func (c *ScClient) connect() {
-	var err error
-	c.conn, _, err = websocket.DefaultDialer.Dial(c.url, nil)
+	conn, _, err := websocket.DefaultDialer.Dial(c.url, nil)
 	if err != nil {
+		if c.isClosed() {
+			return
+		}
 		log.Printf("Failed to connect: %v. Retrying in 5 seconds...", err)
 		time.Sleep(5 * time.Second)
 		go c.connect()
 		return
 	}
 
-	defer c.conn.Close()
+	defer conn.Close()
+	c.onConnected(conn)
 
 	// Process incoming messages
 	for {
@@ -69,8 +82,15 @@ func (c *ScClient) connect() {
 		case <-c.done:
 			return
 		default:
-			_, message, err := c.conn.ReadMessage()
+			_, message, err := conn.ReadMessage()
 			if err != nil {
+				c.mu.Lock()
+				c.conn = nil
+				c.mu.Unlock()
+
+				if c.isClosed() {
+					return
+				}
 				log.Printf("Read error: %v. Reconnecting...", err)
 				time.Sleep(5 * time.Second)
 				go c.connect()
@@ -83,28 +103,69 @@ func (c *ScClient) connect() {
 				continue
 			}
 
-			c.mu.Lock()
 			if response.Event {
-				if event, exists := c.events[response.ID]; exists {
+				c.mu.Lock()
+				event, exists := c.events[response.ID]
+				c.mu.Unlock()
+
+				if exists {
 					payload := response.Payload.([]interface{})
-					event.Callback(
-						ScAddr{Value: int64(payload[0].(float64))},
-						ScAddr{Value: int64(payload[1].(float64))},
-						ScAddr{Value: int64(payload[2].(float64))},
-						response.ID,
-					)
-				}
-			} else {
-				if callback, exists := c.callbacks[response.ID]; exists {
-					callback(response)
-					delete(c.callbacks, response.ID)
+					delivery := eventDelivery{
+						event:   event,
+						elAddr:  ScAddr{Value: int64(payload[0].(float64))},
+						edge:    ScAddr{Value: int64(payload[1].(float64))},
+						other:   ScAddr{Value: int64(payload[2].(float64))},
+						eventID: response.ID,
+					}
+					c.deliveries.push(delivery)
 				}
+				continue
+			}
+
+			c.mu.Lock()
+			if callback, exists := c.callbacks[response.ID]; exists {
+				callback(response)
+				delete(c.callbacks, response.ID)
 			}
 			c.mu.Unlock()
 		}
 	}
 }
 
+// isClosed returns true if client was closed
+func (c *ScClient) isClosed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// onConnected sends messages queued while connection was not established
+// and re-resolves registered keynodes after reconnection
+func (c *ScClient) onConnected(conn *websocket.Conn) {
+	c.mu.Lock()
+	c.conn = conn
+	queue := c.messageQueue
+	c.messageQueue = nil
+	// Queued messages keep their IDs, so responses reach callbacks registered when they were sent
+	for i, message := range queue {
+		if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			log.Printf("Write error: %v", err)
+			c.messageQueue = append(c.messageQueue, queue[i:]...)
+			break
+		}
+	}
+	reconnected := c.connected
+	c.connected = true
+	c.mu.Unlock()
+
+	if reconnected {
+		go c.reresolveKeynodes()
+	}
+}
+
 // SendMessage sends message to SC-machine
 func (c *ScClient) sendMessage(actionType string, payload interface{}, callback func(Response)) {
 	c.mu.Lock()
@@ -122,6 +183,7 @@ func (c *ScClient) sendMessage(actionType string, payload interface{}, callback
 	message, err := json.Marshal(request)
 	if err != nil {
 		log.Printf("Failed to marshal request: %v", err)
+		delete(c.callbacks, request.ID)
 		return
 	}
 
@@ -129,15 +191,11 @@ func (c *ScClient) sendMessage(actionType string, payload interface{}, callback
 		if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
 			log.Printf("Write error: %v", err)
 			// Add to queue for retry
-			c.messageQueue = append(c.messageQueue, func() {
-				c.sendMessage(actionType, payload, callback)
-			})
+			c.messageQueue = append(c.messageQueue, message)
 		}
 	} else {
 		// Add to queue if connection is not established
-		c.messageQueue = append(c.messageQueue, func() {
-			c.sendMessage(actionType, payload, callback)
-		})
+		c.messageQueue = append(c.messageQueue, message)
 	}
 }
 
@@ -309,6 +367,22 @@ func (c *ScClient) SetLinkContents(contents []ScLinkContent) ([]bool, error) {
 
 // GetLinkContents gets link contents
 func (c *ScClient) GetLinkContents(addrs []ScAddr) ([]ScLinkContent, error) {
+	aligned, err := c.getLinkContents(addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make([]ScLinkContent, 0, len(aligned))
+	for _, content := range aligned {
+		if content != nil {
+			contents = append(contents, *content)
+		}
+	}
+	return contents, nil
+}
+
+// getLinkContents gets link contents aligned with addrs, nil for links without content
+func (c *ScClient) getLinkContents(addrs []ScAddr) ([]*ScLinkContent, error) {
 	payload := make([]interface{}, len(addrs))
 	for i, addr := range addrs {
 		payload[i] = map[string]interface{}{
@@ -317,73 +391,141 @@ func (c *ScClient) GetLinkContents(addrs []ScAddr) ([]ScLinkContent, error) {
 		}
 	}
 
-	result := make(chan []ScLinkContent, 1)
+	type linkContents struct {
+		contents []*ScLinkContent
+		err      error
+	}
+
+	result := make(chan linkContents, 1)
 	c.sendMessage("content", payload, func(response Response) {
-		if !response.Status {
-			result <- nil
+		items, ok := response.Payload.([]interface{})
+		if !response.Status || !ok {
+			result <- linkContents{err: errors.New("failed to get link contents")}
+			return
+		}
+		if len(items) != len(addrs) {
+			result <- linkContents{err: CommonError(ErrInvalidState, fmt.Sprintf("got %d link contents for %d links", len(items), len(addrs)))}
 			return
 		}
 
-		contents := make([]ScLinkContent, 0)
-		for _, item := range response.Payload.([]interface{}) {
+		contents := make([]*ScLinkContent, len(items))
+		for i, item := range items {
 			itemMap := item.(map[string]interface{})
 			if value, exists := itemMap["value"]; exists && value != nil {
-				content := ScLinkContent{
+				contents[i] = &ScLinkContent{
 					Data: value,
 					Type: StringToType(itemMap["type"].(string)),
 				}
-				contents = append(contents, content)
 			}
 		}
-		result <- contents
+		result <- linkContents{contents: contents}
+	})
+
+	select {
+	case res := <-result:
+		return res.contents, res.err
+	case <-time.After(30 * time.Second):
+		return nil, errors.New("timeout while getting link contents")
+	}
+}
+
+// FindLinksByContents finds links with content equal to each of data
+func (c *ScClient) FindLinksByContents(data []interface{}) ([][]ScAddr, error) {
+	payload := make([]interface{}, len(data))
+	for i, d := range data {
+		payload[i] = map[string]interface{}{
+			"command": "find",
+			"data":    d,
+		}
+	}
+
+	result := make(chan [][]ScAddr, 1)
+	c.sendMessage("content", payload, func(response Response) {
+		if !response.Status {
+			result <- nil
+			return
+		}
+
+		items := response.Payload.([]interface{})
+		links := make([][]ScAddr, len(items))
+		for i, item := range items {
+			addrs, _ := item.([]interface{})
+			links[i] = make([]ScAddr, len(addrs))
+			for j, a := range addrs {
+				links[i][j] = ScAddr{Value: int64(a.(float64))}
+			}
+		}
+		result <- links
 	})
 
 	select {
 	case res := <-result:
 		if res == nil {
-			return nil, errors.New("failed to get link contents")
+			return nil, errors.New("failed to find links by contents")
 		}
 		return res, nil
 	case <-time.After(30 * time.Second):
-		return nil, errors.New("timeout while getting link contents")
+		return nil, errors.New("timeout while finding links by contents")
 	}
 }
 
-// ResolveKeynodes resolves keynodes
+// ResolveKeynodes resolves keynodes. Identifiers which are not found are absent in result,
+// check them with `addr, found := keynodes[idtf]` or use ResolveKeynodesOrdered
 func (c *ScClient) ResolveKeynodes(params map[string]ScType) (map[string]ScAddr, error) {
-	payload := make([]interface{}, 0, len(params))
-	for id, t := range params {
-		if t.IsValid() {
-			payload = append(payload, map[string]interface{}{
+	requests := make([]KeynodeRequest, 0, len(params))
+	for idtf, t := range params {
+		requests = append(requests, KeynodeRequest{Idtf: idtf, Type: t})
+	}
+
+	results, err := c.ResolveKeynodesOrdered(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]ScAddr, len(results))
+	for _, result := range results {
+		if result.Found {
+			res[result.Idtf] = result.Addr
+		}
+	}
+	return res, nil
+}
+
+// ResolveKeynodesOrdered resolves keynodes and returns results in order of requests
+func (c *ScClient) ResolveKeynodesOrdered(requests []KeynodeRequest) ([]KeynodeResult, error) {
+	if len(requests) == 0 {
+		return []KeynodeResult{}, nil
+	}
+
+	payload := make([]interface{}, len(requests))
+	for i, request := range requests {
+		if request.Type.IsValid() {
+			payload[i] = map[string]interface{}{
 				"command": "resolve",
-				"idtf":    id,
-				"elType":  t.Value,
-			})
+				"idtf":    request.Idtf,
+				"elType":  request.Type.Value,
+			}
 		} else {
-			payload = append(payload, map[string]interface{}{
+			payload[i] = map[string]interface{}{
 				"command": "find",
-				"idtf":    id,
-			})
+				"idtf":    request.Idtf,
+			}
 		}
 	}
 
-	result := make(chan map[string]ScAddr, 1)
+	result := make(chan []KeynodeResult, 1)
 	c.sendMessage("keynodes", payload, func(response Response) {
-		if !response.Status {
+		addrs, ok := response.Payload.([]interface{})
+		if !response.Status || !ok || len(addrs) != len(requests) {
 			result <- nil
 			return
 		}
 
-		addrs := make([]ScAddr, len(response.Payload.([]interface{})))
-		for i, a := range response.Payload.([]interface{}) {
-			addrs[i] = ScAddr{Value: int64(a.(float64))}
-		}
-
-		res := make(map[string]ScAddr)
-		i := 0
-		for id := range params {
-			res[id] = addrs[i]
-			i++
+		res := make([]KeynodeResult, len(requests))
+		for i, a := range addrs {
+			value, _ := a.(float64)
+			addr := ScAddr{Value: int64(value)}
+			res[i] = KeynodeResult{Idtf: requests[i].Idtf, Addr: addr, Found: addr.IsValid()}
 		}
 		result <- res
 	})
@@ -399,11 +541,78 @@ func (c *ScClient) ResolveKeynodes(params map[string]ScType) (map[string]ScAddr,
 	}
 }
 
+// SetTemplateChecks enables validation and normalization of templates before sending
+func (c *ScClient) SetTemplateChecks(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checkTemplates = enabled
+}
+
 // TemplateSearch searches by template
 func (c *ScClient) TemplateSearch(template *ScTemplate) ([]ScTemplateResult, error) {
-	payload := c.prepareTemplatePayload(template)
+	set, err := c.TemplateSearchSet(template)
+	if err != nil {
+		return nil, err
+	}
+	return set.Rows(), nil
+}
+
+// TemplateSearchEach searches by template and executes function for each result until it returns false.
+// It is a convenience iterator: server returns all results in one response, so they are received
+// and kept in memory before the first call of f
+func (c *ScClient) TemplateSearchEach(template *ScTemplate, f func(row ScTemplateResult) bool) error {
+	set, err := c.TemplateSearchSet(template)
+	if err != nil {
+		return err
+	}
+	set.ForEach(f)
+	return nil
+}
+
+// TemplateSearchWithParams searches by template with aliases replaced by addresses from params
+func (c *ScClient) TemplateSearchWithParams(template *ScTemplate, params map[string]ScAddr) ([]ScTemplateResult, error) {
+	set, err := c.templateSearchSet(template, params)
+	if err != nil {
+		return nil, err
+	}
+	return set.Rows(), nil
+}
+
+// TemplateSearchSet searches by template and returns results in columnar form
+func (c *ScClient) TemplateSearchSet(template *ScTemplate) (*ScTemplateResultSet, error) {
+	return c.templateSearchSet(template, nil)
+}
+
+func (c *ScClient) templateSearchSet(template *ScTemplate, params map[string]ScAddr) (*ScTemplateResultSet, error) {
+	result, err := c.sendTemplateSearch(template, params)
+	if err != nil {
+		return nil, err
+	}
+	return waitTemplateSearch(result)
+}
 
-	result := make(chan []ScTemplateResult, 1)
+// sendTemplateSearch sends search request without waiting for response,
+// so several searches can be processed by server at once
+func (c *ScClient) sendTemplateSearch(template *ScTemplate, params map[string]ScAddr) (<-chan *ScTemplateResultSet, error) {
+	template, order, err := c.prepareTemplate(template, params)
+	if err != nil {
+		return nil, err
+	}
+
+	templatePayload, err := c.prepareTemplatePayload(template)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload interface{} = templatePayload
+	if len(params) > 0 {
+		payload = map[string]interface{}{
+			"templ":  templatePayload,
+			"params": c.prepareTemplateParams(params),
+		}
+	}
+
+	result := make(chan *ScTemplateResultSet, 1)
 	c.sendMessage("search_template", payload, func(response Response) {
 		if !response.Status {
 			result <- nil
@@ -414,27 +623,31 @@ func (c *ScClient) TemplateSearch(template *ScTemplate) ([]ScTemplateResult, err
 		aliases := responseData["aliases"].(map[string]interface{})
 		addrsData := responseData["addrs"].([]interface{})
 
-		results := make([]ScTemplateResult, len(addrsData))
-		for i, addrList := range addrsData {
-			addrValues := addrList.([]interface{})
-			addrs := make([]ScAddr, len(addrValues))
-			for j, addr := range addrValues {
-				addrs[j] = ScAddr{Value: int64(addr.(float64))}
-			}
-
-			aliasIndices := make(map[string]int)
-			for alias, index := range aliases {
-				aliasIndices[alias] = int(index.(float64))
-			}
+		set := &ScTemplateResultSet{
+			Aliases: make(map[string]int, len(aliases)),
+		}
+		for alias, index := range aliases {
+			set.Aliases[alias] = int(index.(float64))
+		}
 
-			results[i] = ScTemplateResult{
-				Addrs:   addrs,
-				Indices: aliasIndices,
+		if len(addrsData) > 0 {
+			set.Width = len(addrsData[0].([]interface{}))
+			set.Addrs = make([]ScAddr, 0, set.Width*len(addrsData))
+		}
+		for _, addrList := range addrsData {
+			for _, addr := range addrList.([]interface{}) {
+				set.Addrs = append(set.Addrs, ScAddr{Value: int64(addr.(float64))})
 			}
 		}
-		result <- results
+
+		set.restoreTripleOrder(order)
+		result <- set
 	})
+	return result, nil
+}
 
+// waitTemplateSearch waits for response of search request
+func waitTemplateSearch(result <-chan *ScTemplateResultSet) (*ScTemplateResultSet, error) {
 	select {
 	case res := <-result:
 		if res == nil {
@@ -448,8 +661,18 @@ func (c *ScClient) TemplateSearch(template *ScTemplate) ([]ScTemplateResult, err
 
 // TemplateGenerate generates elements by template
 func (c *ScClient) TemplateGenerate(template *ScTemplate, params map[string]ScAddr) (*ScTemplateResult, error) {
+	template, order, err := c.prepareTemplate(template, params)
+	if err != nil {
+		return nil, err
+	}
+
+	templatePayload, err := c.prepareTemplatePayload(template)
+	if err != nil {
+		return nil, err
+	}
+
 	payload := map[string]interface{}{
-		"templ":  c.prepareTemplatePayload(template),
+		"templ":  templatePayload,
 		"params": c.prepareTemplateParams(params),
 	}
 
@@ -474,9 +697,15 @@ func (c *ScClient) TemplateGenerate(template *ScTemplate, params map[string]ScAd
 			aliasIndices[alias] = int(index.(float64))
 		}
 
-		result <- &ScTemplateResult{
+		set := &ScTemplateResultSet{
+			Aliases: aliasIndices,
+			Width:   len(addrs),
 			Addrs:   addrs,
-			Indices: aliasIndices,
+		}
+		set.restoreTripleOrder(order)
+		result <- &ScTemplateResult{
+			Addrs:   set.Addrs,
+			Indices: set.Aliases,
 		}
 	})
 
@@ -491,19 +720,46 @@ func (c *ScClient) TemplateGenerate(template *ScTemplate, params map[string]ScAd
 	}
 }
 
-func (c *ScClient) prepareTemplatePayload(template *ScTemplate) []interface{} {
+// prepareTemplate validates and normalizes template if checks are enabled.
+// Returns original index of each triple when triples were reordered
+func (c *ScClient) prepareTemplate(template *ScTemplate, params map[string]ScAddr) (*ScTemplate, []int, error) {
+	c.mu.Lock()
+	checkTemplates := c.checkTemplates
+	c.mu.Unlock()
+
+	if !checkTemplates {
+		return template, nil, nil
+	}
+
+	warnings, err := template.Validate(params)
+	for _, warning := range warnings {
+		log.Printf("Template warning: %s", warning)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	normalized, order := template.normalize(params)
+	return normalized, order, nil
+}
+
+func (c *ScClient) prepareTemplatePayload(template *ScTemplate) ([]interface{}, error) {
 	payload := make([]interface{}, len(template.Triples))
 	for i, triple := range template.Triples {
-		payload[i] = []interface{}{
-			c.processTripleItem(triple.Source),
-			c.processTripleItem(triple.Edge),
-			c.processTripleItem(triple.Target),
+		items := make([]interface{}, 3)
+		for j, item := range triple.items() {
+			processed, err := c.processTripleItem(item)
+			if err != nil {
+				return nil, err
+			}
+			items[j] = processed
 		}
+		payload[i] = items
 	}
-	return payload
+	return payload, nil
 }
 
-func (c *ScClient) processTripleItem(item ScTemplateValue) map[string]interface{} {
+func (c *ScClient) processTripleItem(item ScTemplateValue) (map[string]interface{}, error) {
 	result := make(map[string]interface{})
 	if item.Alias != "" {
 		result["alias"] = item.Alias
@@ -520,9 +776,9 @@ func (c *ScClient) processTripleItem(item ScTemplateValue) map[string]interface{
 		result["type"] = "alias"
 		result["value"] = v
 	default:
-		panic("invalid triple item type")
+		return nil, CommonError(ErrInvalidParameters, fmt.Sprintf("invalid triple item type %T", item.Value))
 	}
-	return result
+	return result, nil
 }
 
 func (c *ScClient) prepareTemplateParams(params map[string]ScAddr) map[string]interface{} {
@@ -533,12 +789,105 @@ func (c *ScClient) prepareTemplateParams(params map[string]ScAddr) map[string]in
 	return result
 }
 
-// EventsCreate creates events
+// SetEventVocabulary sets event type names understood by server
+func (c *ScClient) SetEventVocabulary(vocabulary ScEventVocabulary) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.eventVocabulary = vocabulary
+}
+
+// SetServerVersion sets event vocabulary by sc-machine version of server like "0.10.0".
+// Without version vocabulary is detected on first events creation: events rejected by server
+// are sent again with names from the other vocabulary if their elements exist
+func (c *ScClient) SetServerVersion(version string) error {
+	vocabulary := ScEventVocabularyForVersion(version)
+	if vocabulary == ScEventVocabularyUnknown {
+		return InvalidValueError(fmt.Sprintf("unknown server version %q", version))
+	}
+	c.SetEventVocabulary(vocabulary)
+	return nil
+}
+
+// EventVocabulary returns event type names understood by server
+func (c *ScClient) EventVocabulary() ScEventVocabulary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.eventVocabulary
+}
+
+// EventsCreate creates events. Event types are translated to vocabulary of server
 func (c *ScClient) EventsCreate(events []ScEventParams) ([]ScEvent, error) {
+	vocabulary := c.EventVocabulary()
+	if vocabulary != ScEventVocabularyUnknown {
+		return c.eventsCreate(events, vocabulary)
+	}
+
+	// Try vocabulary of passed event types first, then the other one
+	first, second := ScEventVocabularyLegacy, ScEventVocabularyModern
+	for _, event := range events {
+		if event.Type.Vocabulary() == ScEventVocabularyModern {
+			first, second = second, first
+			break
+		}
+	}
+
+	created, err := c.eventsCreate(events, first)
+	if err == nil {
+		c.SetEventVocabulary(first)
+		return created, nil
+	}
+	if !errors.Is(err, errEventsRejected) {
+		return nil, err
+	}
+	if err := c.checkEventsRejectedByType(events, first, second); err != nil {
+		return nil, err
+	}
+
+	created, err = c.eventsCreate(events, second)
+	if err != nil {
+		return nil, err
+	}
+	c.SetEventVocabulary(second)
+	return created, nil
+}
+
+// checkEventsRejectedByType returns error if events could be rejected for other reason than
+// names of their types: elements of events are missing or names are the same in both vocabularies
+func (c *ScClient) checkEventsRejectedByType(events []ScEventParams, rejected, other ScEventVocabulary) error {
+	differ := false
+	addrs := make([]ScAddr, len(events))
+	for i, event := range events {
+		addrs[i] = event.Addr
+		if event.Type.In(rejected) != event.Type.In(other) {
+			differ = true
+		}
+	}
+	if !differ {
+		return errEventsRejected
+	}
+
+	types, err := c.CheckElements(addrs)
+	if err != nil {
+		return err
+	}
+	for i, t := range types {
+		if !t.IsValid() {
+			return CommonError(ErrElementNotFound, fmt.Sprintf("element %v of %s event", addrs[i], events[i].Type))
+		}
+	}
+	return nil
+}
+
+var errEventsRejected = errors.New("failed to create events")
+
+func (c *ScClient) eventsCreate(events []ScEventParams, vocabulary ScEventVocabulary) ([]ScEvent, error) {
 	payload := make([]interface{}, len(events))
 	for i, event := range events {
+		if vocabulary == ScEventVocabularyLegacy && !event.Type.HasLegacy() {
+			return nil, CommonError(ErrInvalidType, fmt.Sprintf("event %s is not supported by legacy server", event.Type))
+		}
 		payload[i] = map[string]interface{}{
-			"type": event.Type,
+			"type": event.Type.In(vocabulary),
 			"addr": event.Addr.Value,
 		}
 	}
@@ -559,9 +908,12 @@ func (c *ScClient) EventsCreate(events []ScEventParams) ([]ScEvent, error) {
 		for i, id := range eventIDs {
 			eventID := int(id.(float64))
 			createdEvents[i] = ScEvent{
-				ID:       eventID,
-				Type:     events[i].Type,
-				Callback: events[i].Callback,
+				ID:            eventID,
+				Type:          events[i].Type,
+				Callback:      events[i].Callback,
+				TypedCallback: events[i].TypedCallback,
+				Filter:        events[i].Filter,
+				dispatch:      events[i].dispatch,
 			}
 			c.events[eventID] = &createdEvents[i]
 		}
@@ -571,7 +923,7 @@ func (c *ScClient) EventsCreate(events []ScEventParams) ([]ScEvent, error) {
 	select {
 	case res := <-result:
 		if res == nil {
-			return nil, errors.New("failed to create events")
+			return nil, errEventsRejected
 		}
 		return res, nil
 	case <-time.After(30 * time.Second):
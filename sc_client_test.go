@@ -0,0 +1,206 @@
+package sc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestServer starts server answering requests with responses of handle
+func newTestServer(tb testing.TB, handle func(request Request) Response) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			var request Request
+			if err := conn.ReadJSON(&request); err != nil {
+				return
+			}
+			response := handle(request)
+			response.ID = request.ID
+			if err := conn.WriteJSON(response); err != nil {
+				return
+			}
+		}
+	}))
+	tb.Cleanup(server.Close)
+	return server
+}
+
+func testServerURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// newKeynodesServer starts server answering keynodes requests with new addresses
+// and other requests with empty successful responses
+func newKeynodesServer(tb testing.TB) *httptest.Server {
+	var next atomic.Int64
+	return newTestServer(tb, func(request Request) Response {
+		response := Response{Status: true, Payload: []interface{}{}}
+		if request.Type == "keynodes" {
+			addrs := make([]interface{}, len(request.Payload.([]interface{})))
+			for i := range addrs {
+				addrs[i] = next.Add(1)
+			}
+			response.Payload = addrs
+		}
+		return response
+	})
+}
+
+func TestQueuedMessagesKeepCallbacks(t *testing.T) {
+	server := newKeynodesServer(t)
+	client := &ScClient{
+		url:        testServerURL(server),
+		callbacks:  make(map[int]func(Response)),
+		events:     make(map[int]*ScEvent),
+		done:       make(chan struct{}),
+		deliveries: newEventDeliveries(),
+	}
+	defer client.Close()
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := client.CheckElements([]ScAddr{{Value: 1}})
+		result <- err
+	}()
+	for queued := 0; queued == 0; {
+		time.Sleep(time.Millisecond)
+		client.mu.Lock()
+		queued = len(client.messageQueue)
+		client.mu.Unlock()
+	}
+
+	go client.connect()
+	if err := <-result; err != nil {
+		t.Fatalf("queued request failed: %v", err)
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.callbacks) != 0 || client.eventID != 1 {
+		t.Errorf("queued request is sent again under new ID, callbacks left: %d", len(client.callbacks))
+	}
+}
+
+func TestReresolveKeynodesWithReaders(t *testing.T) {
+	server := newKeynodesServer(t)
+	client := NewScClient(testServerURL(server))
+	defer client.Close()
+
+	type personKeynodes struct {
+		Person ScAddr `sc:"concept_person,ScTypeNodeConstClass"`
+	}
+	keynodes, err := RegisterKeynodes[personKeynodes](client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	person := keynodes.Get()
+	common, err := client.CommonKeynodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	action := common.Action
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			client.reresolveKeynodes()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if !keynodes.Get().Person.IsValid() {
+			t.Error("keynode is invalid while reading")
+		}
+		if !common.Action.Equal(action) || !person.Person.IsValid() {
+			t.Fatal("returned keynodes are changed")
+		}
+	}
+	wg.Wait()
+
+	if updated, _ := client.CommonKeynodes(); updated.Action.Equal(action) || keynodes.Get().Person.Equal(person.Person) {
+		t.Error("keynodes are not resolved again")
+	}
+}
+
+func TestCommonKeynodesAreRegisteredOnce(t *testing.T) {
+	server := newKeynodesServer(t)
+	client := NewScClient(testServerURL(server))
+	defer client.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if common, err := client.CommonKeynodes(); err != nil || !common.Action.IsValid() {
+				t.Errorf("unexpected keynodes %+v, %v", common, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	client.keynodes.mu.Lock()
+	defer client.keynodes.mu.Unlock()
+	if len(client.keynodes.targets) != 1 {
+		t.Errorf("common keynodes are registered %d times", len(client.keynodes.targets))
+	}
+}
+
+func TestResolveKeynodesKeepsOrderOfRequests(t *testing.T) {
+	addrs := map[string]int64{"concept_a": 10, "concept_b": 20, "concept_c": 30}
+	server := newTestServer(t, func(request Request) Response {
+		commands := request.Payload.([]interface{})
+		payload := make([]interface{}, len(commands))
+		for i, command := range commands {
+			payload[i] = addrs[command.(map[string]interface{})["idtf"].(string)]
+		}
+		return Response{Status: true, Payload: payload}
+	})
+	client := NewScClient(testServerURL(server))
+	defer client.Close()
+
+	requests := []KeynodeRequest{
+		{Idtf: "concept_c"},
+		{Idtf: "concept_missing"},
+		{Idtf: "concept_a", Type: ScType{Value: ScTypeNodeConstClass}},
+		{Idtf: "concept_b"},
+	}
+	results, err := client.ResolveKeynodesOrdered(requests)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(requests) {
+		t.Fatalf("expected %d results, got %v", len(requests), results)
+	}
+	for i, result := range results {
+		expected := addrs[requests[i].Idtf]
+		if result.Idtf != requests[i].Idtf || result.Addr.Value != expected || result.Found != (expected != 0) {
+			t.Errorf("result %d is not aligned with request %q: %+v", i, requests[i].Idtf, result)
+		}
+	}
+
+	keynodes, err := client.ResolveKeynodes(map[string]ScType{"concept_a": {}, "concept_b": {}, "concept_missing": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keynodes) != 2 || keynodes["concept_a"].Value != 10 || keynodes["concept_b"].Value != 20 {
+		t.Errorf("unexpected keynodes %v", keynodes)
+	}
+	if _, found := keynodes["concept_missing"]; found {
+		t.Error("missing keynode is present in result")
+	}
+}
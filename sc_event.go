@@ -1,13 +1,32 @@
 package sc
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // ScEventCallbackFunc represents event callback function
 type ScEventCallbackFunc func(elAddr, edge, other ScAddr, eventID int)
 
+// ScEventTypedCallbackFunc represents event callback function receiving decoded payload
+type ScEventTypedCallbackFunc func(payload ScEventPayload, eventID int)
+
 // ScEvent represents SC event
 type ScEvent struct {
-	ID       int
-	Type     ScEventType
-	Callback ScEventCallbackFunc
+	ID            int
+	Type          ScEventType
+	Callback      ScEventCallbackFunc
+	TypedCallback ScEventTypedCallbackFunc
+	Filter        *ScEventFilter
+
+	// dispatch replaces filter and callbacks for events shared by local listeners
+	dispatch func(types *eventTypes, d eventDelivery) func()
+}
+
+// String returns string representation of event
+func (e ScEvent) String() string {
+	return fmt.Sprintf("ScEvent{ID: %d, Type: %s}", e.ID, e.Type)
 }
 
 // IsValid checks if event is valid
@@ -17,7 +36,111 @@ func (e ScEvent) IsValid() bool {
 
 // ScEventParams represents event parameters
 type ScEventParams struct {
-	Addr     ScAddr
-	Type     ScEventType
-	Callback ScEventCallbackFunc
+	Addr          ScAddr
+	Type          ScEventType
+	Callback      ScEventCallbackFunc
+	TypedCallback ScEventTypedCallbackFunc
+	Filter        *ScEventFilter
+
+	dispatch func(types *eventTypes, d eventDelivery) func()
+}
+
+var legacyToModernEvents = map[ScEventType]ScEventType{
+	ScEventAddOutgoingEdge:    ScEventAfterGenerateOutgoingArc,
+	ScEventAddIngoingEdge:     ScEventAfterGenerateIncomingArc,
+	ScEventRemoveOutgoingEdge: ScEventBeforeEraseOutgoingArc,
+	ScEventRemoveIngoingEdge:  ScEventBeforeEraseIncomingArc,
+	ScEventRemoveElement:      ScEventBeforeEraseElement,
+	ScEventChangeContent:      ScEventBeforeChangeLinkContent,
+}
+
+var modernToLegacyEvents = map[ScEventType]ScEventType{
+	ScEventAfterGenerateOutgoingArc: ScEventAddOutgoingEdge,
+	ScEventAfterGenerateIncomingArc: ScEventAddIngoingEdge,
+	ScEventBeforeEraseOutgoingArc:   ScEventRemoveOutgoingEdge,
+	ScEventBeforeEraseIncomingArc:   ScEventRemoveIngoingEdge,
+	ScEventBeforeEraseElement:       ScEventRemoveElement,
+	ScEventBeforeChangeLinkContent:  ScEventChangeContent,
+}
+
+// modernOnlyEvents are events of undirected edges legacy servers don't have
+var modernOnlyEvents = map[ScEventType]bool{
+	ScEventAfterGenerateEdge: true,
+	ScEventBeforeEraseEdge:   true,
+}
+
+// Vocabulary returns vocabulary event type name belongs to
+func (t ScEventType) Vocabulary() ScEventVocabulary {
+	if _, exists := legacyToModernEvents[t]; exists {
+		return ScEventVocabularyLegacy
+	}
+	if _, exists := modernToLegacyEvents[t]; exists || modernOnlyEvents[t] {
+		return ScEventVocabularyModern
+	}
+	return ScEventVocabularyUnknown
+}
+
+// Modern returns event type name used by modern sc-machine servers
+func (t ScEventType) Modern() ScEventType {
+	if modern, exists := legacyToModernEvents[t]; exists {
+		return modern
+	}
+	return t
+}
+
+// Legacy returns event type name used by legacy sc-machine servers.
+// Undirected edge events have no legacy analogue and are returned as is, see HasLegacy
+func (t ScEventType) Legacy() ScEventType {
+	if legacy, exists := modernToLegacyEvents[t]; exists {
+		return legacy
+	}
+	return t
+}
+
+// HasLegacy checks if event type can be sent to legacy sc-machine servers
+func (t ScEventType) HasLegacy() bool {
+	return !modernOnlyEvents[t]
+}
+
+// isErase checks if event is raised when element or edge is erased
+func (t ScEventType) isErase() bool {
+	switch t.Modern() {
+	case ScEventBeforeEraseOutgoingArc, ScEventBeforeEraseIncomingArc, ScEventBeforeEraseEdge, ScEventBeforeEraseElement:
+		return true
+	}
+	return false
+}
+
+// ScEventVocabularyForVersion returns event vocabulary of sc-machine version like "0.10.0"
+func ScEventVocabularyForVersion(version string) ScEventVocabulary {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 {
+		return ScEventVocabularyUnknown
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return ScEventVocabularyUnknown
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return ScEventVocabularyUnknown
+	}
+
+	if major > 0 || minor >= 10 {
+		return ScEventVocabularyModern
+	}
+	return ScEventVocabularyLegacy
+}
+
+// In returns event type name of specified vocabulary
+func (t ScEventType) In(vocabulary ScEventVocabulary) ScEventType {
+	switch vocabulary {
+	case ScEventVocabularyLegacy:
+		return t.Legacy()
+	case ScEventVocabularyModern:
+		return t.Modern()
+	default:
+		return t
+	}
 }
@@ -0,0 +1,121 @@
+package sc
+
+import (
+	"log"
+	"sync"
+)
+
+// eventDelivery represents received event waiting for callbacks
+type eventDelivery struct {
+	event               *ScEvent
+	elAddr, edge, other ScAddr
+	eventID             int
+}
+
+// eventBatchSize is a maximum number of received events filtered together
+const eventBatchSize = 1024
+
+// eventDeliveries is unbounded queue of received events, so reading of responses
+// is never blocked by callbacks or filters waiting for them
+type eventDeliveries struct {
+	mu      sync.Mutex
+	pending []eventDelivery
+	ready   chan struct{}
+}
+
+func newEventDeliveries() *eventDeliveries {
+	return &eventDeliveries{ready: make(chan struct{}, 1)}
+}
+
+func (q *eventDeliveries) push(d eventDelivery) {
+	q.mu.Lock()
+	q.pending = append(q.pending, d)
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *eventDeliveries) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// take removes up to eventBatchSize events from queue
+func (q *eventDeliveries) take() []eventDelivery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	batch := q.pending
+	if len(batch) > eventBatchSize {
+		batch = batch[:eventBatchSize:eventBatchSize]
+		q.pending = q.pending[eventBatchSize:]
+		q.signal()
+	} else {
+		q.pending = nil
+	}
+	return batch
+}
+
+// dispatchEvents runs event callbacks outside of reading goroutine,
+// so callbacks are able to send requests and wait for responses
+func (c *ScClient) dispatchEvents() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-c.deliveries.ready:
+			c.deliverEvents(c.deliveries.take())
+		}
+	}
+}
+
+// deliverEvents runs callbacks of batch in order of receiving. Filters of all events are
+// evaluated before callbacks: types are fetched with one request and template searches are pipelined
+func (c *ScClient) deliverEvents(batch []eventDelivery) {
+	types := &eventTypes{batch: batch}
+	callbacks := make([]func(), len(batch))
+	for i, d := range batch {
+		callbacks[i] = d.event.prepare(c, types, d)
+	}
+	for _, callback := range callbacks {
+		if callback != nil {
+			callback()
+		}
+	}
+}
+
+// prepare evaluates filter of event and returns function running callbacks if event matches
+func (e *ScEvent) prepare(c *ScClient, types *eventTypes, d eventDelivery) func() {
+	if e.dispatch != nil {
+		return e.dispatch(types, d)
+	}
+
+	var match *eventMatch
+	if e.Filter != nil {
+		var err error
+		if match, err = e.Filter.prepare(c, types, d); err != nil {
+			log.Printf("Failed to filter event %d: %v", d.eventID, err)
+			return nil
+		}
+	}
+
+	return func() {
+		if match != nil {
+			matched, err := match.result()
+			if err != nil {
+				log.Printf("Failed to filter event %d: %v", d.eventID, err)
+				return
+			}
+			if !matched {
+				return
+			}
+		}
+		if e.Callback != nil {
+			e.Callback(d.elAddr, d.edge, d.other, d.eventID)
+		}
+		if e.TypedCallback != nil {
+			e.TypedCallback(DecodeScEvent(e.Type, d.elAddr, d.edge, d.other), d.eventID)
+		}
+	}
+}
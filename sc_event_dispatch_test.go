@@ -0,0 +1,51 @@
+package sc_test
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sc "github.com/temapriemnik/go-sc-client"
+	"github.com/temapriemnik/go-sc-client/agenttest"
+)
+
+func TestFilteredListenerReceivesBurstOfEvents(t *testing.T) {
+	kit := agenttest.New(t)
+	set := kit.Keynode("test_set", sc.ScTypeNodeConst)
+
+	var received atomic.Int64
+	listener, err := kit.Client.AddFilteredListener(set, sc.ScEventAddOutgoingEdge, func(elAddr, edge, other sc.ScAddr, eventID int) {
+		received.Add(1)
+	}, &sc.ScEventFilter{OtherType: &sc.ScType{Value: sc.ScTypeNodeConst}})
+	if err != nil {
+		t.Fatalf("failed to add listener: %v", err)
+	}
+	defer listener.Close()
+
+	// More events than the reader used to buffer, each one needs types from server
+	const count = 3000
+	construction := &sc.ScConstruction{}
+	for i := 0; i < count; i++ {
+		node := fmt.Sprintf("node%d", i)
+		if err := construction.CreateNode(sc.ScType{Value: sc.ScTypeNodeConst}, node); err != nil {
+			t.Fatal(err)
+		}
+		if err := construction.CreateEdge(sc.ScType{Value: sc.ScTypeArcPosConstPerm}, set, node, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	kit.Construct(construction)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for received.Load() < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("received %d of %d events", received.Load(), count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := kit.Client.CheckElements([]sc.ScAddr{set}); err != nil {
+		t.Fatalf("client is not responsive after events: %v", err)
+	}
+}
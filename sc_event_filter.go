@@ -0,0 +1,119 @@
+package sc
+
+import "fmt"
+
+// ScEventFilter represents declarative filter of element events evaluated by client.
+// Only set conditions are checked, types are compared as masks
+type ScEventFilter struct {
+	// EdgeType is not checked for remove events, their edges are erased before types are fetched
+	EdgeType  *ScType
+	OtherType *ScType
+	Other     ScAddr
+	Template  func(elAddr, edge, other ScAddr) *ScTemplate
+}
+
+// Match checks if event satisfies filter
+func (f *ScEventFilter) Match(c *ScClient, elAddr, edge, other ScAddr) (bool, error) {
+	delivery := eventDelivery{elAddr: elAddr, edge: edge, other: other}
+	match, err := f.prepare(c, &eventTypes{batch: []eventDelivery{delivery}}, delivery)
+	if err != nil {
+		return false, err
+	}
+	return match.result()
+}
+
+// eventMatch represents filter evaluation which may wait for template search
+type eventMatch struct {
+	matched bool
+	search  <-chan *ScTemplateResultSet
+}
+
+// prepare checks conditions known without template search and sends search if it is needed,
+// so searches of all events of a batch are pipelined
+func (f *ScEventFilter) prepare(c *ScClient, types *eventTypes, d eventDelivery) (*eventMatch, error) {
+	if f.Other.IsValid() && !f.Other.Equal(d.other) {
+		return &eventMatch{}, nil
+	}
+
+	if f.EdgeType != nil && (d.event == nil || !d.event.Type.isErase()) {
+		edgeType, err := types.get(c, d.edge)
+		if err != nil {
+			return nil, err
+		}
+		if (f.EdgeType.Value & edgeType.Value) != f.EdgeType.Value {
+			return &eventMatch{}, nil
+		}
+	}
+	if f.OtherType != nil {
+		otherType, err := types.get(c, d.other)
+		if err != nil {
+			return nil, err
+		}
+		if (f.OtherType.Value & otherType.Value) != f.OtherType.Value {
+			return &eventMatch{}, nil
+		}
+	}
+
+	if f.Template == nil {
+		return &eventMatch{matched: true}, nil
+	}
+	template := f.Template(d.elAddr, d.edge, d.other)
+	if template == nil {
+		return &eventMatch{}, nil
+	}
+	search, err := c.sendTemplateSearch(template, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &eventMatch{search: search}, nil
+}
+
+// result waits for template search if filter needs it
+func (m *eventMatch) result() (bool, error) {
+	if m.search == nil {
+		return m.matched, nil
+	}
+	set, err := waitTemplateSearch(m.search)
+	if err != nil {
+		return false, err
+	}
+	return set.Len() > 0, nil
+}
+
+// eventTypes fetches types of edges and other elements of all events in batch
+// with one request when the first filter needs them
+type eventTypes struct {
+	batch   []eventDelivery
+	fetched bool
+	types   map[int64]ScType
+	err     error
+}
+
+func (t *eventTypes) get(c *ScClient, addr ScAddr) (ScType, error) {
+	if !t.fetched {
+		t.fetched = true
+		t.types = make(map[int64]ScType)
+
+		var addrs []ScAddr
+		for _, d := range t.batch {
+			for _, a := range []ScAddr{d.edge, d.other} {
+				if _, exists := t.types[a.Value]; !exists {
+					t.types[a.Value] = ScType{}
+					addrs = append(addrs, a)
+				}
+			}
+		}
+
+		var types []ScType
+		types, t.err = c.CheckElements(addrs)
+		if t.err == nil && len(types) != len(addrs) {
+			t.err = CommonError(ErrInvalidState, fmt.Sprintf("got %d types of %d event elements", len(types), len(addrs)))
+		}
+		if t.err == nil {
+			for i, a := range addrs {
+				t.types[a.Value] = types[i]
+			}
+		}
+	}
+	return t.types[addr.Value], t.err
+}
@@ -0,0 +1,56 @@
+package sc
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestScEventFilterEdgeTypeOfRemoveEvents(t *testing.T) {
+	var checks atomic.Int64
+	server := newTestServer(t, func(request Request) Response {
+		checks.Add(1)
+		// Erased edge is reported as missing
+		return Response{Status: true, Payload: []interface{}{0, ScTypeNodeConst}}
+	})
+	client := NewScClient(testServerURL(server))
+	defer client.Close()
+
+	filter := &ScEventFilter{EdgeType: &ScType{Value: ScTypeArcPosConstPerm}}
+	d := eventDelivery{
+		event:  &ScEvent{Type: ScEventBeforeEraseOutgoingArc},
+		elAddr: ScAddr{Value: 1},
+		edge:   ScAddr{Value: 2},
+		other:  ScAddr{Value: 3},
+	}
+	match, err := filter.prepare(client, &eventTypes{batch: []eventDelivery{d}}, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matched, err := match.result(); !matched || err != nil || checks.Load() != 0 {
+		t.Errorf("edge type is checked for remove event: %v, %v", matched, err)
+	}
+
+	d.event = &ScEvent{Type: ScEventAddOutgoingEdge}
+	match, err = filter.prepare(client, &eventTypes{batch: []eventDelivery{d}}, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matched, _ := match.result(); matched || checks.Load() != 1 {
+		t.Errorf("edge type is not checked for add event")
+	}
+}
+
+func TestScEventFilterTypesCountMismatch(t *testing.T) {
+	server := newTestServer(t, func(request Request) Response {
+		return Response{Status: true, Payload: []interface{}{ScTypeArcPosConstPerm}}
+	})
+	client := NewScClient(testServerURL(server))
+	defer client.Close()
+
+	filter := &ScEventFilter{OtherType: &ScType{Value: ScTypeNodeConst}}
+	_, err := filter.Match(client, ScAddr{Value: 1}, ScAddr{Value: 2}, ScAddr{Value: 3})
+	if err == nil || !strings.HasPrefix(err.Error(), ErrInvalidState.Error()) {
+		t.Errorf("expected invalid state error, got %v", err)
+	}
+}
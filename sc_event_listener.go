@@ -0,0 +1,333 @@
+package sc
+
+import (
+	"errors"
+	"log"
+	"sync"
+)
+
+// ScEventFilterFunc decides if listener should receive event
+type ScEventFilterFunc func(elAddr, edge, other ScAddr) bool
+
+// ScEventListener represents local listener of server event shared by all
+// listeners of the same element and event type
+type ScEventListener struct {
+	client   *ScClient
+	key      sharedEventKey
+	id       int
+	callback ScEventCallbackFunc
+	filter   ScEventFilterFunc
+	match    *ScEventFilter
+}
+
+type sharedEventKey struct {
+	addr      int64
+	eventType ScEventType
+}
+
+// sharedEvent represents server event with reference counted local listeners
+type sharedEvent struct {
+	event     ScEvent
+	listeners map[int]*ScEventListener
+	ready     chan struct{}
+	err       error
+	// closing is set while server event left without listeners is destroyed,
+	// it is closed when destroying is finished
+	closing chan struct{}
+}
+
+// listeners holds shared server events of client
+type listeners struct {
+	mu     sync.Mutex
+	events map[sharedEventKey]*sharedEvent
+	nextID int
+}
+
+// AddListener adds local listener of element event. Only one server event is created
+// for each element and event type, it is destroyed when the last listener is closed.
+// Filter may be nil
+func (c *ScClient) AddListener(addr ScAddr, eventType ScEventType, callback ScEventCallbackFunc, filter ScEventFilterFunc) (*ScEventListener, error) {
+	return c.addListener(addr, eventType, callback, filter, nil)
+}
+
+// AddFilteredListener adds local listener of element event with declarative filter.
+// Types needed by filters of all listeners are fetched with one request per batch of received events
+func (c *ScClient) AddFilteredListener(addr ScAddr, eventType ScEventType, callback ScEventCallbackFunc, filter *ScEventFilter) (*ScEventListener, error) {
+	return c.addListener(addr, eventType, callback, nil, filter)
+}
+
+func (c *ScClient) addListener(addr ScAddr, eventType ScEventType, callback ScEventCallbackFunc, filter ScEventFilterFunc, match *ScEventFilter) (*ScEventListener, error) {
+	listeners, err := c.addListeners([]listenerParams{{addr: addr, eventType: eventType, callback: callback, filter: filter, match: match}})
+	if err != nil {
+		return nil, err
+	}
+	return listeners[0], nil
+}
+
+// listenerParams represents listener to be added
+type listenerParams struct {
+	addr      ScAddr
+	eventType ScEventType
+	callback  ScEventCallbackFunc
+	filter    ScEventFilterFunc
+	match     *ScEventFilter
+}
+
+// addListeners adds local listeners. Missing server events of all listeners are created with one request
+func (c *ScClient) addListeners(params []listenerParams) ([]*ScEventListener, error) {
+	for _, p := range params {
+		if p.callback == nil {
+			return nil, CommonError(ErrInvalidParameters, "callback should be set")
+		}
+	}
+
+	// Listeners are returned in order of params, so listeners added by retries are put in place
+	listeners := make([]*ScEventListener, len(params))
+	remaining := make([]int, len(params))
+	for i := range remaining {
+		remaining[i] = i
+	}
+	for len(remaining) > 0 {
+		batch := make([]listenerParams, len(remaining))
+		for i, index := range remaining {
+			batch[i] = params[index]
+		}
+
+		added, err := c.tryAddListeners(batch)
+		var retry []int
+		for i, listener := range added {
+			if listener == nil {
+				retry = append(retry, remaining[i])
+			} else {
+				listeners[remaining[i]] = listener
+			}
+		}
+		if err != nil {
+			var created []*ScEventListener
+			for _, listener := range listeners {
+				if listener != nil {
+					created = append(created, listener)
+				}
+			}
+			c.closeListeners(created)
+			return nil, err
+		}
+		remaining = retry
+	}
+	return listeners, nil
+}
+
+// tryAddListeners adds listeners whose shared events exist or are created by this call.
+// Returns listeners in order of params, nil for listeners whose events were destroyed
+// while waiting, they should be added again
+func (c *ScClient) tryAddListeners(params []listenerParams) ([]*ScEventListener, error) {
+	l := &c.listeners
+	shared := make([]*sharedEvent, len(params))
+	closing := make([]chan struct{}, len(params))
+	var created []*sharedEvent
+	var createdKeys []sharedEventKey
+	var createdParams []ScEventParams
+
+	l.mu.Lock()
+	if l.events == nil {
+		l.events = make(map[sharedEventKey]*sharedEvent)
+	}
+	for i, p := range params {
+		key := sharedEventKey{addr: p.addr.Value, eventType: p.eventType.Modern()}
+		if existing, exists := l.events[key]; exists {
+			shared[i] = existing
+			closing[i] = existing.closing
+			continue
+		}
+
+		event := &sharedEvent{
+			listeners: make(map[int]*ScEventListener),
+			ready:     make(chan struct{}),
+		}
+		l.events[key] = event
+		shared[i] = event
+		created = append(created, event)
+		createdKeys = append(createdKeys, key)
+		createdParams = append(createdParams, ScEventParams{
+			Addr: p.addr,
+			Type: p.eventType,
+			dispatch: func(types *eventTypes, d eventDelivery) func() {
+				return c.dispatchShared(event, types, d)
+			},
+		})
+	}
+	l.mu.Unlock()
+
+	if len(created) > 0 {
+		c.createSharedEvents(createdKeys, createdParams, created)
+	}
+
+	listeners := make([]*ScEventListener, len(params))
+	var firstErr error
+	for i, p := range params {
+		<-shared[i].ready
+		if closing[i] != nil {
+			// Event is kept if destroying fails, otherwise it is created again
+			<-closing[i]
+			continue
+		}
+
+		l.mu.Lock()
+		key := sharedEventKey{addr: p.addr.Value, eventType: p.eventType.Modern()}
+		switch {
+		case shared[i].err != nil:
+			if firstErr == nil {
+				firstErr = shared[i].err
+			}
+		case l.events[key] != shared[i]:
+			// Event was destroyed while waiting, create it again
+		default:
+			l.nextID++
+			listener := &ScEventListener{
+				client:   c,
+				key:      key,
+				id:       l.nextID,
+				callback: p.callback,
+				filter:   p.filter,
+				match:    p.match,
+			}
+			shared[i].listeners[listener.id] = listener
+			listeners[i] = listener
+		}
+		l.mu.Unlock()
+	}
+	return listeners, firstErr
+}
+
+func (c *ScClient) createSharedEvents(keys []sharedEventKey, params []ScEventParams, shared []*sharedEvent) {
+	events, err := c.EventsCreate(params)
+
+	c.listeners.mu.Lock()
+	defer c.listeners.mu.Unlock()
+
+	for i, event := range shared {
+		if err != nil {
+			event.err = err
+			delete(c.listeners.events, keys[i])
+		} else {
+			event.event = events[i]
+		}
+		close(event.ready)
+	}
+}
+
+// dispatchShared evaluates declarative filters of listeners present when event is received
+// and returns function running callbacks of matched listeners
+func (c *ScClient) dispatchShared(shared *sharedEvent, types *eventTypes, d eventDelivery) func() {
+	c.listeners.mu.Lock()
+	receivers := make([]*ScEventListener, 0, len(shared.listeners))
+	for _, listener := range shared.listeners {
+		receivers = append(receivers, listener)
+	}
+	c.listeners.mu.Unlock()
+
+	matches := make([]*eventMatch, len(receivers))
+	for i, listener := range receivers {
+		if listener.match == nil {
+			continue
+		}
+		match, err := listener.match.prepare(c, types, d)
+		if err != nil {
+			log.Printf("Failed to filter event %d: %v", d.eventID, err)
+			match = &eventMatch{}
+		}
+		matches[i] = match
+	}
+
+	return func() {
+		for i, listener := range receivers {
+			if listener.filter != nil && !listener.filter(d.elAddr, d.edge, d.other) {
+				continue
+			}
+			if matches[i] != nil {
+				matched, err := matches[i].result()
+				if err != nil {
+					log.Printf("Failed to filter event %d: %v", d.eventID, err)
+					continue
+				}
+				if !matched {
+					continue
+				}
+			}
+			listener.callback(d.elAddr, d.edge, d.other, d.eventID)
+		}
+	}
+}
+
+// ListenersCount returns number of local listeners of element event
+func (c *ScClient) ListenersCount(addr ScAddr, eventType ScEventType) int {
+	c.listeners.mu.Lock()
+	defer c.listeners.mu.Unlock()
+
+	if shared, exists := c.listeners.events[sharedEventKey{addr: addr.Value, eventType: eventType.Modern()}]; exists {
+		return len(shared.listeners)
+	}
+	return 0
+}
+
+// Close removes listener and destroys server event if it was the last one
+func (l *ScEventListener) Close() error {
+	return l.client.closeListeners([]*ScEventListener{l})
+}
+
+// closeListeners removes listeners and destroys server events left without listeners with one request.
+// Events are kept until they are destroyed, so they are reused and destroyed again if destroying fails
+func (c *ScClient) closeListeners(closed []*ScEventListener) error {
+	listeners := &c.listeners
+	listeners.mu.Lock()
+
+	var eventIDs []int
+	var closing []*sharedEvent
+	var closingKeys []sharedEventKey
+	invalid := false
+	for _, l := range closed {
+		shared, exists := listeners.events[l.key]
+		if !exists || shared.listeners[l.id] == nil {
+			continue
+		}
+
+		delete(shared.listeners, l.id)
+		if len(shared.listeners) > 0 {
+			continue
+		}
+
+		if !shared.event.IsValid() {
+			delete(listeners.events, l.key)
+			invalid = true
+			continue
+		}
+		shared.closing = make(chan struct{})
+		closing = append(closing, shared)
+		closingKeys = append(closingKeys, l.key)
+		eventIDs = append(eventIDs, shared.event.ID)
+	}
+	listeners.mu.Unlock()
+
+	var err error
+	if len(eventIDs) > 0 {
+		err = c.EventsDestroy(eventIDs)
+	}
+
+	listeners.mu.Lock()
+	for i, shared := range closing {
+		if err == nil {
+			delete(listeners.events, closingKeys[i])
+		}
+		close(shared.closing)
+		shared.closing = nil
+	}
+	listeners.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if invalid {
+		return errors.New("failed to destroy events: event is not created")
+	}
+	return nil
+}
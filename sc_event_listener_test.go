@@ -0,0 +1,141 @@
+package sc
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newEventsServer starts server creating and destroying events. Destroying fails while failDestroy is set
+func newEventsServer(tb testing.TB, created, destroyed *atomic.Int64, failDestroy *atomic.Bool) string {
+	var next atomic.Int64
+	return testServerURL(newTestServer(tb, func(request Request) Response {
+		payload, _ := request.Payload.(map[string]interface{})
+		if items, ok := payload["create"].([]interface{}); ok {
+			created.Add(1)
+			ids := make([]interface{}, len(items))
+			for i := range ids {
+				ids[i] = next.Add(1)
+			}
+			return Response{Status: true, Payload: ids}
+		}
+		destroyed.Add(1)
+		return Response{Status: !failDestroy.Load()}
+	}))
+}
+
+func TestListenersShareServerEvent(t *testing.T) {
+	var created, destroyed atomic.Int64
+	var failDestroy atomic.Bool
+	client := NewScClient(newEventsServer(t, &created, &destroyed, &failDestroy))
+	defer client.Close()
+
+	addr := ScAddr{Value: 1}
+	callback := func(elAddr, edge, other ScAddr, eventID int) {}
+	first, err := client.AddListener(addr, ScEventAddOutgoingEdge, callback, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.AddListener(addr, ScEventAfterGenerateOutgoingArc, callback, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Load() != 1 || client.ListenersCount(addr, ScEventAddOutgoingEdge) != 2 {
+		t.Fatalf("event is not shared: %d events for %d listeners", created.Load(), client.ListenersCount(addr, ScEventAddOutgoingEdge))
+	}
+
+	if err := first.Close(); err != nil || destroyed.Load() != 0 {
+		t.Fatalf("event is destroyed with listener left: %v", err)
+	}
+	if err := first.Close(); err != nil || client.ListenersCount(addr, ScEventAddOutgoingEdge) != 1 {
+		t.Errorf("second close of listener changed count: %v", err)
+	}
+
+	// Event is kept when destroying fails and reused by next listener
+	failDestroy.Store(true)
+	if err := second.Close(); err == nil {
+		t.Fatal("expected destroy error")
+	}
+	client.listeners.mu.Lock()
+	shared := client.listeners.events[second.key]
+	client.listeners.mu.Unlock()
+	if shared == nil {
+		t.Fatal("event is dropped after failed destroy")
+	}
+	client.mu.Lock()
+	_, tracked := client.events[shared.event.ID]
+	client.mu.Unlock()
+	if !tracked {
+		t.Error("event is not tracked after failed destroy")
+	}
+
+	third, err := client.AddListener(addr, ScEventAddOutgoingEdge, callback, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Load() != 1 {
+		t.Errorf("kept event is not reused, %d events are created", created.Load())
+	}
+
+	failDestroy.Store(false)
+	if err := third.Close(); err != nil {
+		t.Fatal(err)
+	}
+	client.listeners.mu.Lock()
+	left := len(client.listeners.events)
+	client.listeners.mu.Unlock()
+	if destroyed.Load() != 2 || left != 0 {
+		t.Errorf("event is not destroyed: %d requests, %d events left", destroyed.Load(), left)
+	}
+}
+
+func TestAddListenersKeepsOrderOfRetriedListeners(t *testing.T) {
+	var created, destroyed atomic.Int64
+	var failDestroy atomic.Bool
+	client := NewScClient(newEventsServer(t, &created, &destroyed, &failDestroy))
+	defer client.Close()
+
+	callback := func(elAddr, edge, other ScAddr, eventID int) {}
+	existing, err := client.AddListener(ScAddr{Value: 2}, ScEventRemoveElement, callback, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Event of the second listener is being destroyed, so it is added after the others
+	closing := make(chan struct{})
+	client.listeners.mu.Lock()
+	client.listeners.events[existing.key].closing = closing
+	client.listeners.mu.Unlock()
+
+	type result struct {
+		listeners []*ScEventListener
+		err       error
+	}
+	added := make(chan result, 1)
+	go func() {
+		listeners, err := client.addListeners([]listenerParams{
+			{addr: ScAddr{Value: 1}, eventType: ScEventRemoveElement, callback: callback},
+			{addr: ScAddr{Value: 2}, eventType: ScEventRemoveElement, callback: callback},
+			{addr: ScAddr{Value: 3}, eventType: ScEventRemoveElement, callback: callback},
+		})
+		added <- result{listeners, err}
+	}()
+	for created.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	client.listeners.mu.Lock()
+	client.listeners.events[existing.key].closing = nil
+	client.listeners.mu.Unlock()
+	close(closing)
+
+	res := <-added
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+	for i, listener := range res.listeners {
+		if listener.key.addr != int64(i+1) {
+			t.Errorf("listener %d belongs to %d", i, listener.key.addr)
+		}
+	}
+}
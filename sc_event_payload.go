@@ -0,0 +1,89 @@
+package sc
+
+// ScEventPayload represents decoded event payload. It is one of
+// ScEventConnectorPayload, ScEventEraseElementPayload and ScEventLinkContentPayload
+type ScEventPayload interface {
+	EventType() ScEventType
+}
+
+// ScEventConnectorPayload represents generation or erasure of arc or edge
+type ScEventConnectorPayload struct {
+	Type         ScEventType
+	Subscription ScAddr
+	Connector    ScAddr
+	Source       ScAddr
+	Target       ScAddr
+	Erased       bool
+}
+
+// EventType returns type of event in modern vocabulary
+func (p ScEventConnectorPayload) EventType() ScEventType {
+	return p.Type
+}
+
+// ScEventEraseElementPayload represents erasure of element
+type ScEventEraseElementPayload struct {
+	Type    ScEventType
+	Element ScAddr
+}
+
+// EventType returns type of event in modern vocabulary
+func (p ScEventEraseElementPayload) EventType() ScEventType {
+	return p.Type
+}
+
+// ScEventLinkContentPayload represents change of link content
+type ScEventLinkContentPayload struct {
+	Type ScEventType
+	Link ScAddr
+}
+
+// EventType returns type of event in modern vocabulary
+func (p ScEventLinkContentPayload) EventType() ScEventType {
+	return p.Type
+}
+
+// DecodeScEvent converts raw event addresses into typed payload.
+// For undirected edges subscription element is reported as source
+func DecodeScEvent(eventType ScEventType, elAddr, edge, other ScAddr) ScEventPayload {
+	t := eventType.Modern()
+	switch t {
+	case ScEventAfterGenerateOutgoingArc, ScEventBeforeEraseOutgoingArc,
+		ScEventAfterGenerateEdge, ScEventBeforeEraseEdge:
+		return ScEventConnectorPayload{
+			Type:         t,
+			Subscription: elAddr,
+			Connector:    edge,
+			Source:       elAddr,
+			Target:       other,
+			Erased:       t == ScEventBeforeEraseOutgoingArc || t == ScEventBeforeEraseEdge,
+		}
+	case ScEventAfterGenerateIncomingArc, ScEventBeforeEraseIncomingArc:
+		return ScEventConnectorPayload{
+			Type:         t,
+			Subscription: elAddr,
+			Connector:    edge,
+			Source:       other,
+			Target:       elAddr,
+			Erased:       t == ScEventBeforeEraseIncomingArc,
+		}
+	case ScEventBeforeEraseElement:
+		return ScEventEraseElementPayload{
+			Type:    t,
+			Element: elAddr,
+		}
+	case ScEventBeforeChangeLinkContent:
+		return ScEventLinkContentPayload{
+			Type: t,
+			Link: elAddr,
+		}
+	default:
+		return ScEventConnectorPayload{
+			Type:         t,
+			Subscription: elAddr,
+			Connector:    edge,
+			Source:       elAddr,
+			Target:       other,
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package sc
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestScEventTypeVocabularies(t *testing.T) {
+	for legacy, modern := range legacyToModernEvents {
+		if legacy.Vocabulary() != ScEventVocabularyLegacy || modern.Vocabulary() != ScEventVocabularyModern {
+			t.Errorf("wrong vocabularies of %s and %s", legacy, modern)
+		}
+		if legacy.In(ScEventVocabularyModern) != modern || modern.In(ScEventVocabularyLegacy) != legacy {
+			t.Errorf("%s and %s are not mapped to each other", legacy, modern)
+		}
+		if legacy.In(ScEventVocabularyUnknown) != legacy {
+			t.Errorf("%s is changed by unknown vocabulary", legacy)
+		}
+	}
+
+	for _, undirected := range []ScEventType{ScEventAfterGenerateEdge, ScEventBeforeEraseEdge} {
+		if undirected.HasLegacy() || undirected.Legacy() != undirected || undirected.Vocabulary() != ScEventVocabularyModern {
+			t.Errorf("undirected edge event %s is mapped to %s", undirected, undirected.Legacy())
+		}
+	}
+	if ScEventType("custom").Vocabulary() != ScEventVocabularyUnknown {
+		t.Error("custom event type should have unknown vocabulary")
+	}
+}
+
+func TestScEventVocabularyForVersion(t *testing.T) {
+	for version, expected := range map[string]ScEventVocabulary{
+		"0.9.0":   ScEventVocabularyLegacy,
+		"v0.10.0": ScEventVocabularyModern,
+		"1.0":     ScEventVocabularyModern,
+		"0":       ScEventVocabularyUnknown,
+		"x.10":    ScEventVocabularyUnknown,
+	} {
+		if vocabulary := ScEventVocabularyForVersion(version); vocabulary != expected {
+			t.Errorf("%q: expected %d, got %d", version, expected, vocabulary)
+		}
+	}
+}
+
+// newLegacyEventsServer starts server accepting legacy event names only.
+// Elements with addrs below 100 exist. Returns server and counter of events requests
+func newLegacyEventsServer(tb testing.TB) (*httptest.Server, *atomic.Int64) {
+	var requests atomic.Int64
+	server := newTestServer(tb, func(request Request) Response {
+		response := Response{Status: true}
+		switch request.Type {
+		case "check_elements":
+			types := make([]interface{}, len(request.Payload.([]interface{})))
+			for i, addr := range request.Payload.([]interface{}) {
+				if addr.(float64) < 100 {
+					types[i] = ScTypeNodeConst
+				} else {
+					types[i] = 0
+				}
+			}
+			response.Payload = types
+		case "events":
+			requests.Add(1)
+			items, _ := request.Payload.(map[string]interface{})["create"].([]interface{})
+			ids := make([]interface{}, len(items))
+			for i, item := range items {
+				event := item.(map[string]interface{})
+				if ScEventType(event["type"].(string)).Vocabulary() != ScEventVocabularyLegacy || event["addr"].(float64) >= 100 {
+					response.Status = false
+				}
+				ids[i] = i + 1
+			}
+			response.Payload = ids
+		}
+		return response
+	})
+	return server, &requests
+}
+
+func TestEventsCreateFallsBackOnRejectedTypes(t *testing.T) {
+	server, requests := newLegacyEventsServer(t)
+	url := testServerURL(server)
+
+	client := NewScClient(url)
+	defer client.Close()
+	events, err := client.EventsCreate([]ScEventParams{{Addr: ScAddr{Value: 1}, Type: ScEventAfterGenerateOutgoingArc}})
+	if err != nil || len(events) != 1 || events[0].Type != ScEventAfterGenerateOutgoingArc {
+		t.Fatalf("events are not created with legacy names: %v, %v", events, err)
+	}
+	if client.EventVocabulary() != ScEventVocabularyLegacy || requests.Load() != 2 {
+		t.Errorf("unexpected vocabulary %d after %d requests", client.EventVocabulary(), requests.Load())
+	}
+
+	// Events of missing elements are not retried
+	requests.Store(0)
+	missing := NewScClient(url)
+	defer missing.Close()
+	_, err = missing.EventsCreate([]ScEventParams{{Addr: ScAddr{Value: 500}, Type: ScEventAfterGenerateOutgoingArc}})
+	if err == nil || !strings.HasPrefix(err.Error(), ErrElementNotFound.Error()) {
+		t.Errorf("expected element not found error, got %v", err)
+	}
+	if missing.EventVocabulary() != ScEventVocabularyUnknown || requests.Load() != 1 {
+		t.Errorf("rejected events are retried: vocabulary %d after %d requests", missing.EventVocabulary(), requests.Load())
+	}
+
+	// Undirected edge events are not sent to legacy server
+	requests.Store(0)
+	_, err = client.EventsCreate([]ScEventParams{{Addr: ScAddr{Value: 1}, Type: ScEventAfterGenerateEdge}})
+	if err == nil || !strings.HasPrefix(err.Error(), ErrInvalidType.Error()) || requests.Load() != 0 {
+		t.Errorf("expected invalid type error without request, got %v", err)
+	}
+
+	versioned := NewScClient(url)
+	defer versioned.Close()
+	if err := versioned.SetServerVersion("0.9.0"); err != nil || versioned.EventVocabulary() != ScEventVocabularyLegacy {
+		t.Errorf("vocabulary is not set by version: %v", err)
+	}
+	if err := versioned.SetServerVersion("latest"); err == nil {
+		t.Error("unknown version is accepted")
+	}
+}
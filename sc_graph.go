@@ -0,0 +1,207 @@
+package sc
+
+import "fmt"
+
+// GraphElement represents element of graph loaded from knowledge base
+type GraphElement struct {
+	Addr ScAddr
+	Type ScType
+	// Source and Target are ends of edge, invalid addrs for nodes and links
+	Source ScAddr
+	Target ScAddr
+	// Content is content of link, nil if it was not loaded
+	Content *ScLinkContent
+}
+
+// IsEdge returns true if element is edge
+func (e *GraphElement) IsEdge() bool {
+	return e.Type.IsEdge()
+}
+
+// Graph represents fragment of knowledge base kept in memory.
+// Every edge of graph has both ends in graph
+type Graph struct {
+	// Root is element graph was loaded around
+	Root ScAddr
+	// Truncated is set when elements were skipped because of limits
+	Truncated bool
+
+	elements map[int64]*GraphElement
+	order    []int64
+	out      map[int64][]int64
+	in       map[int64][]int64
+	nodes    int
+	edges    int
+	// removed is a number of addrs of removed elements left in order
+	removed int
+}
+
+// NewGraph creates empty graph
+func NewGraph(root ScAddr) *Graph {
+	return &Graph{
+		Root:     root,
+		elements: make(map[int64]*GraphElement),
+		out:      make(map[int64][]int64),
+		in:       make(map[int64][]int64),
+	}
+}
+
+// AddElement adds node or link to graph. Existing element is returned as is
+func (g *Graph) AddElement(addr ScAddr, t ScType) (*GraphElement, error) {
+	if !addr.IsValid() {
+		return nil, InvalidValueError("invalid addr of graph element")
+	}
+	if t.IsEdge() {
+		return nil, CommonError(ErrInvalidType, fmt.Sprintf("edge %v should be added with its ends", addr))
+	}
+	if el, exists := g.elements[addr.Value]; exists {
+		return el, nil
+	}
+
+	el := &GraphElement{Addr: addr, Type: t}
+	g.elements[addr.Value] = el
+	g.appendOrder(addr)
+	g.nodes++
+	return el, nil
+}
+
+// AddEdge adds edge between elements of graph. Existing edge is returned as is
+func (g *Graph) AddEdge(addr ScAddr, t ScType, src, trg ScAddr) (*GraphElement, error) {
+	if !addr.IsValid() {
+		return nil, InvalidValueError("invalid addr of graph edge")
+	}
+	if el, exists := g.elements[addr.Value]; exists {
+		return el, nil
+	}
+	if !g.Contains(src) || !g.Contains(trg) {
+		return nil, CommonError(ErrElementNotFound, fmt.Sprintf("ends %v and %v of edge %v in graph", src, trg, addr))
+	}
+
+	el := &GraphElement{Addr: addr, Type: t, Source: src, Target: trg}
+	g.elements[addr.Value] = el
+	g.appendOrder(addr)
+	g.out[src.Value] = append(g.out[src.Value], addr.Value)
+	g.in[trg.Value] = append(g.in[trg.Value], addr.Value)
+	g.edges++
+	return el, nil
+}
+
+// Remove removes element with incident edges. Returns removed elements,
+// incident edges go before elements they are incident to
+func (g *Graph) Remove(addr ScAddr) []*GraphElement {
+	el, exists := g.elements[addr.Value]
+	if !exists {
+		return nil
+	}
+
+	var removed []*GraphElement
+	for _, edge := range append(append([]int64(nil), g.out[addr.Value]...), g.in[addr.Value]...) {
+		removed = append(removed, g.Remove(ScAddr{Value: edge})...)
+	}
+
+	if el.IsEdge() {
+		g.out[el.Source.Value] = without(g.out[el.Source.Value], addr.Value)
+		g.in[el.Target.Value] = without(g.in[el.Target.Value], addr.Value)
+		g.edges--
+	} else {
+		g.nodes--
+	}
+	delete(g.elements, addr.Value)
+	delete(g.out, addr.Value)
+	delete(g.in, addr.Value)
+
+	g.removed++
+	if g.removed > len(g.order)/2 {
+		g.compact()
+	}
+	return append(removed, el)
+}
+
+// appendOrder appends addr to order. Order is compacted first, so addr removed before is not repeated
+func (g *Graph) appendOrder(addr ScAddr) {
+	if g.removed > 0 {
+		g.compact()
+	}
+	g.order = append(g.order, addr.Value)
+}
+
+// compact drops addrs of removed elements from order
+func (g *Graph) compact() {
+	order := make([]int64, 0, len(g.elements))
+	for _, addr := range g.order {
+		if _, exists := g.elements[addr]; exists {
+			order = append(order, addr)
+		}
+	}
+	g.order = order
+	g.removed = 0
+}
+
+func without(addrs []int64, addr int64) []int64 {
+	result := addrs[:0]
+	for _, a := range addrs {
+		if a != addr {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// Contains checks if element is in graph
+func (g *Graph) Contains(addr ScAddr) bool {
+	_, exists := g.elements[addr.Value]
+	return exists
+}
+
+// Element returns element of graph, nil if there is no one
+func (g *Graph) Element(addr ScAddr) *GraphElement {
+	return g.elements[addr.Value]
+}
+
+// Elements returns all elements in order they were added
+func (g *Graph) Elements() []*GraphElement {
+	elements := make([]*GraphElement, 0, len(g.elements))
+	for _, addr := range g.order {
+		if el, exists := g.elements[addr]; exists {
+			elements = append(elements, el)
+		}
+	}
+	return elements
+}
+
+// Nodes returns nodes and links in order they were added
+func (g *Graph) Nodes() []*GraphElement {
+	nodes := make([]*GraphElement, 0, g.nodes)
+	for _, addr := range g.order {
+		if el, exists := g.elements[addr]; exists && !el.IsEdge() {
+			nodes = append(nodes, el)
+		}
+	}
+	return nodes
+}
+
+// Edges returns edges in order they were added
+func (g *Graph) Edges() []*GraphElement {
+	edges := make([]*GraphElement, 0, g.edges)
+	for _, addr := range g.order {
+		if el, exists := g.elements[addr]; exists && el.IsEdge() {
+			edges = append(edges, el)
+		}
+	}
+	return edges
+}
+
+// Len returns number of elements in graph
+func (g *Graph) Len() int {
+	return len(g.elements)
+}
+
+// NodesCount returns number of nodes and links in graph
+func (g *Graph) NodesCount() int {
+	return g.nodes
+}
+
+// EdgesCount returns number of edges in graph
+func (g *Graph) EdgesCount() int {
+	return g.edges
+}
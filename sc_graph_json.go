@@ -0,0 +1,108 @@
+package sc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// graphJSON is serialized form of graph
+type graphJSON struct {
+	Root      int64              `json:"root"`
+	Truncated bool               `json:"truncated,omitempty"`
+	Elements  []graphElementJSON `json:"elements"`
+}
+
+type graphElementJSON struct {
+	Addr    int64             `json:"addr"`
+	Type    int               `json:"type"`
+	Source  int64             `json:"source,omitempty"`
+	Target  int64             `json:"target,omitempty"`
+	Content *graphContentJSON `json:"content,omitempty"`
+}
+
+type graphContentJSON struct {
+	Data interface{} `json:"data"`
+	Type string      `json:"type"`
+}
+
+// MarshalJSON serializes elements of graph in order they were added
+func (g *Graph) MarshalJSON() ([]byte, error) {
+	data := graphJSON{
+		Root:      g.Root.Value,
+		Truncated: g.Truncated,
+		Elements:  make([]graphElementJSON, 0, g.Len()),
+	}
+	for _, el := range g.Elements() {
+		item := graphElementJSON{
+			Addr:   el.Addr.Value,
+			Type:   el.Type.Value,
+			Source: el.Source.Value,
+			Target: el.Target.Value,
+		}
+		if el.Content != nil {
+			item.Content = &graphContentJSON{Data: el.Content.Data, Type: el.Content.TypeToStr()}
+		}
+		data.Elements = append(data.Elements, item)
+	}
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON restores graph keeping order of elements. Edges may go before their ends
+func (g *Graph) UnmarshalJSON(b []byte) error {
+	var data graphJSON
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+
+	restored := NewGraph(ScAddr{Value: data.Root})
+	restored.Truncated = data.Truncated
+
+	var edges []graphElementJSON
+	for _, item := range data.Elements {
+		t := ScType{Value: item.Type}
+		if t.IsEdge() {
+			edges = append(edges, item)
+			continue
+		}
+		el, err := restored.AddElement(ScAddr{Value: item.Addr}, t)
+		if err != nil {
+			return err
+		}
+		if item.Content != nil {
+			addr := el.Addr
+			el.Content = &ScLinkContent{Data: item.Content.Data, Type: StringToType(item.Content.Type), Addr: &addr}
+		}
+	}
+
+	// Edges going to edges are added after their ends
+	for len(edges) > 0 {
+		var postponed []graphElementJSON
+		for _, item := range edges {
+			src, trg := ScAddr{Value: item.Source}, ScAddr{Value: item.Target}
+			if !restored.Contains(src) || !restored.Contains(trg) {
+				postponed = append(postponed, item)
+				continue
+			}
+			if _, err := restored.AddEdge(ScAddr{Value: item.Addr}, ScType{Value: item.Type}, src, trg); err != nil {
+				return err
+			}
+		}
+		if len(postponed) == len(edges) {
+			return CommonError(ErrElementNotFound, fmt.Sprintf("ends of %d edges in graph", len(postponed)))
+		}
+		edges = postponed
+	}
+
+	// Elements are listed in serialized order, not in order ends were added in
+	listed := make(map[int64]bool, len(data.Elements))
+	restored.order = restored.order[:0]
+	for _, item := range data.Elements {
+		if !listed[item.Addr] {
+			listed[item.Addr] = true
+			restored.order = append(restored.order, item.Addr)
+		}
+	}
+
+	*g = *restored
+	return nil
+}
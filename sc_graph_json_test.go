@@ -0,0 +1,84 @@
+package sc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGraphJSONRoundTrip(t *testing.T) {
+	g := testGraph(t)
+	g.Truncated = true
+	link, err := g.AddElement(ScAddr{Value: 5}, ScType{Value: ScTypeLinkConst})
+	if err != nil {
+		t.Fatal(err)
+	}
+	link.Content = &ScLinkContent{Data: "text", Type: ScLinkContentString}
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored Graph
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatal(err)
+	}
+
+	if !restored.Root.Equal(g.Root) || !restored.Truncated || restored.NodesCount() != 5 || restored.EdgesCount() != 3 {
+		t.Errorf("unexpected restored graph %s", data)
+	}
+	for _, el := range g.Elements() {
+		other := restored.Element(el.Addr)
+		if other == nil || other.Type != el.Type || !other.Source.Equal(el.Source) || !other.Target.Equal(el.Target) {
+			t.Errorf("element %v is restored as %+v", el.Addr, other)
+		}
+	}
+	if content := restored.Element(ScAddr{Value: 5}).Content; content == nil || content.Data != "text" || content.Type != ScLinkContentString {
+		t.Errorf("unexpected content %+v", content)
+	}
+	if in := restored.In(ScAddr{Value: 10}); len(in) != 1 || in[0].Addr.Value != 12 {
+		t.Errorf("arc to edge is not restored: %v", graphAddrs(in))
+	}
+
+	again, err := json.Marshal(&restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("graph is changed by round trip:\n%s\n%s", data, again)
+	}
+}
+
+func TestGraphUnmarshalJSON(t *testing.T) {
+	// Edges go before their ends, arc 12 goes before edge 10 it ends in
+	var g Graph
+	if err := json.Unmarshal([]byte(`{"root": 1, "elements": [
+		{"addr": 12, "type": 2224, "source": 4, "target": 10},
+		{"addr": 10, "type": 40, "source": 1, "target": 2},
+		{"addr": 1, "type": 33},
+		{"addr": 2, "type": 33},
+		{"addr": 4, "type": 33}
+	]}`), &g); err != nil {
+		t.Fatal(err)
+	}
+	if g.Len() != 5 || g.EdgesCount() != 2 || g.Root.Value != 1 || g.Truncated {
+		t.Errorf("unexpected graph %d %d %v", g.Len(), g.EdgesCount(), g.Root)
+	}
+	if elements := graphAddrs(g.Elements()); elements[0] != 12 || elements[4] != 4 {
+		t.Errorf("order of elements is not kept: %v", elements)
+	}
+
+	err := json.Unmarshal([]byte(`{"root": 1, "elements": [
+		{"addr": 1, "type": 33},
+		{"addr": 10, "type": 40, "source": 1, "target": 2}
+	]}`), &g)
+	if err == nil || !strings.HasPrefix(err.Error(), ErrElementNotFound.Error()) {
+		t.Errorf("expected element not found error, got %v", err)
+	}
+	if g.Len() != 5 {
+		t.Error("graph is changed by failed unmarshalling")
+	}
+	if err := json.Unmarshal([]byte(`{"elements": 1}`), &g); err == nil {
+		t.Error("invalid json is unmarshalled")
+	}
+}
@@ -0,0 +1,202 @@
+package sc
+
+import "fmt"
+
+// graphMatcher searches template in graph by backtracking over triples
+type graphMatcher struct {
+	graph   *Graph
+	triples [][3]ScTemplateValue
+	binding map[string]ScAddr
+	row     []ScAddr
+	done    []bool
+	set     *ScTemplateResultSet
+}
+
+// TemplateSearch searches template in graph without requests to server. Results have the same form
+// as results of ScClient.TemplateSearchSet. Variable types match elements of constant types
+func (g *Graph) TemplateSearch(template *ScTemplate, params map[string]ScAddr) (*ScTemplateResultSet, error) {
+	m := &graphMatcher{
+		graph:   g,
+		triples: make([][3]ScTemplateValue, len(template.Triples)),
+		binding: make(map[string]ScAddr, len(params)),
+		row:     make([]ScAddr, 3*len(template.Triples)),
+		done:    make([]bool, len(template.Triples)),
+		set: &ScTemplateResultSet{
+			Aliases: make(map[string]int),
+			Width:   3 * len(template.Triples),
+		},
+	}
+	for alias, addr := range params {
+		m.binding[alias] = addr
+	}
+
+	for i, triple := range template.Triples {
+		m.triples[i] = [3]ScTemplateValue{triple.Source, triple.Edge, triple.Target}
+		for j, item := range m.triples[i] {
+			switch item.Value.(type) {
+			case ScAddr, ScType, string:
+			default:
+				return nil, CommonError(ErrInvalidParameters, fmt.Sprintf("invalid triple item type %T", item.Value))
+			}
+			if _, exists := m.set.Aliases[item.Alias]; item.Alias != "" && !exists {
+				m.set.Aliases[item.Alias] = 3*i + j
+			}
+		}
+	}
+
+	if len(m.triples) > 0 {
+		m.step(len(m.triples))
+	}
+	return m.set, nil
+}
+
+// bound returns address of item known before matching
+func (m *graphMatcher) bound(item ScTemplateValue) (ScAddr, bool) {
+	switch v := item.Value.(type) {
+	case ScAddr:
+		return v, true
+	case string:
+		addr, exists := m.binding[v]
+		return addr, exists
+	}
+	if item.Alias != "" {
+		addr, exists := m.binding[item.Alias]
+		return addr, exists
+	}
+	return ScAddr{}, false
+}
+
+func (m *graphMatcher) step(left int) {
+	if left == 0 {
+		m.set.Addrs = append(m.set.Addrs, m.row...)
+		return
+	}
+
+	i := m.nextTriple()
+	m.done[i] = true
+	defer func() { m.done[i] = false }()
+
+	for _, edge := range m.candidates(m.triples[i]) {
+		for _, addrs := range orientations(edge) {
+			m.matchTriple(i, addrs, left)
+		}
+	}
+}
+
+// matchTriple binds aliases of triple i to addrs and continues search if they match
+func (m *graphMatcher) matchTriple(i int, addrs [3]ScAddr, left int) {
+	var added []string
+	matched := true
+	for j, item := range m.triples[i] {
+		alias, ok := m.match(item, addrs[j])
+		if !ok {
+			matched = false
+			break
+		}
+		if alias != "" {
+			m.binding[alias] = addrs[j]
+			added = append(added, alias)
+		}
+	}
+
+	if matched {
+		copy(m.row[3*i:], addrs[:])
+		m.step(left - 1)
+	}
+	for _, alias := range added {
+		delete(m.binding, alias)
+	}
+}
+
+// orientations returns source, edge and target in orders triple may match them.
+// Undirected edges match in both directions
+func orientations(edge *GraphElement) [][3]ScAddr {
+	addrs := [][3]ScAddr{{edge.Source, edge.Addr, edge.Target}}
+	if edge.Type.Value&ScTypeUEdgeCommon != 0 && !edge.Source.Equal(edge.Target) {
+		addrs = append(addrs, [3]ScAddr{edge.Target, edge.Addr, edge.Source})
+	}
+	return addrs
+}
+
+// nextTriple returns unprocessed triple with most known items
+func (m *graphMatcher) nextTriple() int {
+	best, bestScore := -1, -1
+	for i, triple := range m.triples {
+		if m.done[i] {
+			continue
+		}
+		score := 0
+		for j, item := range triple {
+			if _, known := m.bound(item); known {
+				score += 1 + 2*(j%2)
+			}
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// candidates returns edges which may match triple
+func (m *graphMatcher) candidates(triple [3]ScTemplateValue) []*GraphElement {
+	if addr, known := m.bound(triple[1]); known {
+		if edge := m.graph.Element(addr); edge != nil && edge.IsEdge() {
+			return []*GraphElement{edge}
+		}
+		return nil
+	}
+	if addr, known := m.bound(triple[0]); known {
+		return withUndirected(m.graph.Out(addr), m.graph.In(addr))
+	}
+	if addr, known := m.bound(triple[2]); known {
+		return withUndirected(m.graph.In(addr), m.graph.Out(addr))
+	}
+	return m.graph.Edges()
+}
+
+// withUndirected appends undirected edges going in the other direction to edges.
+// Loops are already in edges
+func withUndirected(edges, reversed []*GraphElement) []*GraphElement {
+	for _, edge := range reversed {
+		if edge.Type.Value&ScTypeUEdgeCommon != 0 && !edge.Source.Equal(edge.Target) {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// match checks element against template item. Returns alias to bind
+func (m *graphMatcher) match(item ScTemplateValue, addr ScAddr) (string, bool) {
+	if known, exists := m.bound(item); exists {
+		if !known.Equal(addr) {
+			return "", false
+		}
+		if _, aliased := m.binding[item.Alias]; item.Alias != "" && !aliased {
+			return item.Alias, true
+		}
+		return "", true
+	}
+
+	switch v := item.Value.(type) {
+	case string:
+		return v, true
+	case ScType:
+		if !graphTypeMatches(v, m.graph.Element(addr).Type) {
+			return "", false
+		}
+	}
+	return item.Alias, true
+}
+
+// graphTypeMatches checks element type against template type
+func graphTypeMatches(templateType, elementType ScType) bool {
+	if !templateType.IsValid() {
+		return true
+	}
+	expected := templateType
+	if expected.IsVar() {
+		expected = expected.AsConst()
+	}
+	return elementType.Value&expected.Value == expected.Value
+}
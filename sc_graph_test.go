@@ -0,0 +1,170 @@
+package sc
+
+import (
+	"strings"
+	"testing"
+)
+
+// testGraph builds graph 1 -(10)-> 2 -(11)-> 3 with arc 12 from relation 4 to edge 10
+func testGraph(tb testing.TB) *Graph {
+	tb.Helper()
+
+	g := NewGraph(ScAddr{Value: 1})
+	for _, node := range []struct {
+		addr int64
+		t    int
+	}{{1, ScTypeNodeConst}, {2, ScTypeNodeConst}, {3, ScTypeNodeConstClass}, {4, ScTypeNodeConstNoRole}} {
+		if _, err := g.AddElement(ScAddr{Value: node.addr}, ScType{Value: node.t}); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	for _, edge := range []struct {
+		addr, src, trg int64
+		t              int
+	}{{10, 1, 2, ScTypeDEdgeConst}, {11, 2, 3, ScTypeArcPosConstPerm}, {12, 4, 10, ScTypeArcPosConstPerm}} {
+		if _, err := g.AddEdge(ScAddr{Value: edge.addr}, ScType{Value: edge.t}, ScAddr{Value: edge.src}, ScAddr{Value: edge.trg}); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return g
+}
+
+func graphAddrs(elements []*GraphElement) []int64 {
+	result := make([]int64, len(elements))
+	for i, el := range elements {
+		result[i] = el.Addr.Value
+	}
+	return result
+}
+
+func TestGraphAdd(t *testing.T) {
+	g := testGraph(t)
+
+	if g.Len() != 7 || g.NodesCount() != 4 || g.EdgesCount() != 3 {
+		t.Errorf("unexpected counts %d %d %d", g.Len(), g.NodesCount(), g.EdgesCount())
+	}
+	if el, err := g.AddElement(ScAddr{Value: 1}, ScType{Value: ScTypeNodeConstClass}); err != nil || el.Type.Value != ScTypeNodeConst {
+		t.Errorf("existing element is replaced: %+v, %v", el, err)
+	}
+
+	if _, err := g.AddElement(ScAddr{}, ScType{Value: ScTypeNodeConst}); err == nil {
+		t.Error("element with invalid addr is added")
+	}
+	if _, err := g.AddElement(ScAddr{Value: 20}, ScType{Value: ScTypeArcPosConstPerm}); err == nil || !strings.HasPrefix(err.Error(), ErrInvalidType.Error()) {
+		t.Errorf("expected invalid type error, got %v", err)
+	}
+	if _, err := g.AddEdge(ScAddr{Value: 20}, ScType{Value: ScTypeArcPosConstPerm}, ScAddr{Value: 1}, ScAddr{Value: 30}); err == nil || !strings.HasPrefix(err.Error(), ErrElementNotFound.Error()) {
+		t.Errorf("expected element not found error, got %v", err)
+	}
+}
+
+func TestGraphRemove(t *testing.T) {
+	g := testGraph(t)
+
+	// Outgoing edges go first, arc ending in removed edge goes before it
+	removed := graphAddrs(g.Remove(ScAddr{Value: 2}))
+	if len(removed) != 4 || removed[0] != 11 || removed[1] != 12 || removed[2] != 10 || removed[3] != 2 {
+		t.Errorf("unexpected removal order %v", removed)
+	}
+	if g.Len() != 3 || g.NodesCount() != 3 || g.EdgesCount() != 0 {
+		t.Errorf("unexpected counts %d %d %d", g.Len(), g.NodesCount(), g.EdgesCount())
+	}
+	if len(g.Out(ScAddr{Value: 1})) != 0 || len(g.Out(ScAddr{Value: 4})) != 0 || len(g.In(ScAddr{Value: 3})) != 0 {
+		t.Error("removed edges are left in incidence lists")
+	}
+	if g.Remove(ScAddr{Value: 2}) != nil {
+		t.Error("removed element is removed again")
+	}
+
+	// Element added again after removal is listed once
+	if _, err := g.AddElement(ScAddr{Value: 2}, ScType{Value: ScTypeNodeConst}); err != nil {
+		t.Fatal(err)
+	}
+	if elements := graphAddrs(g.Elements()); len(elements) != 4 || elements[3] != 2 {
+		t.Errorf("unexpected elements %v", elements)
+	}
+	if nodes := graphAddrs(g.Nodes()); len(nodes) != 4 || len(g.Edges()) != 0 {
+		t.Errorf("unexpected nodes %v", nodes)
+	}
+}
+
+func TestGraphTemplateSearch(t *testing.T) {
+	g := testGraph(t)
+
+	template := &ScTemplate{}
+	template.TripleWithRelation(
+		ScType{Value: ScTypeNodeVar},
+		ScType{Value: ScTypeDEdgeVar},
+		[]interface{}{ScType{Value: ScTypeNodeVar}, "_trg"},
+		ScType{Value: ScTypeArcPosVarPerm},
+		ScAddr{Value: 4},
+	)
+	set, err := g.TemplateSearch(template, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Len() != 1 || set.Row(0).Get("_trg").Value != 2 || set.Row(0).Get(1).Value != 10 {
+		t.Errorf("unexpected results %v", set.Addrs)
+	}
+
+	// Params bind aliases, types of variables are checked against constant types
+	set, err = g.TemplateSearch(template, map[string]ScAddr{"_trg": {Value: 3}})
+	if err != nil || set.Len() != 0 {
+		t.Errorf("params are not applied: %v, %v", set, err)
+	}
+	class := &ScTemplate{}
+	class.Triple(ScType{Value: ScTypeNodeVar}, ScType{Value: ScTypeArcPosVarPerm}, ScType{Value: ScTypeNodeVarClass})
+	if set, err := g.TemplateSearch(class, nil); err != nil || set.Len() != 1 || set.Row(0).Get(2).Value != 3 {
+		t.Errorf("unexpected class results %v, %v", set, err)
+	}
+
+	invalid := &ScTemplate{Triples: []ScTemplateTriple{{Source: ScTemplateValue{Value: 1}}}}
+	if _, err := g.TemplateSearch(invalid, nil); err == nil || !strings.HasPrefix(err.Error(), ErrInvalidParameters.Error()) {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+}
+
+func TestGraphTemplateSearchUndirected(t *testing.T) {
+	g := NewGraph(ScAddr{Value: 1})
+	for _, addr := range []int64{1, 2, 3} {
+		if _, err := g.AddElement(ScAddr{Value: addr}, ScType{Value: ScTypeNodeConst}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := g.AddEdge(ScAddr{Value: 10}, ScType{Value: ScTypeUEdgeCommon | ScTypeConst}, ScAddr{Value: 1}, ScAddr{Value: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.AddEdge(ScAddr{Value: 11}, ScType{Value: ScTypeDEdgeConst}, ScAddr{Value: 2}, ScAddr{Value: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Undirected edge matches with ends swapped
+	for _, triple := range [][3]interface{}{
+		{ScAddr{Value: 2}, ScType{Value: ScTypeUEdgeCommon | ScTypeVar}, []interface{}{ScType{Value: ScTypeNodeVar}, "_other"}},
+		{[]interface{}{ScType{Value: ScTypeNodeVar}, "_other"}, ScType{Value: ScTypeUEdgeCommon | ScTypeVar}, ScAddr{Value: 2}},
+		{[]interface{}{ScType{Value: ScTypeNodeVar}, "_other"}, ScAddr{Value: 10}, ScAddr{Value: 2}},
+	} {
+		template := &ScTemplate{}
+		template.Triple(triple[0], triple[1], triple[2])
+		set, err := g.TemplateSearch(template, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if set.Len() != 1 || set.Row(0).Get("_other").Value != 1 || set.Row(0).Get(1).Value != 10 {
+			t.Errorf("unexpected results %v", set.Addrs)
+		}
+	}
+
+	both := &ScTemplate{}
+	both.Triple(ScType{Value: ScTypeNodeVar}, ScType{Value: ScTypeUEdgeCommon | ScTypeVar}, ScType{Value: ScTypeNodeVar})
+	if set, err := g.TemplateSearch(both, nil); err != nil || set.Len() != 2 || set.Row(0).Get(0).Value != 1 || set.Row(1).Get(0).Value != 2 {
+		t.Errorf("undirected edge is not matched in both directions: %v, %v", set, err)
+	}
+
+	// Directed edges are matched in their direction only
+	directed := &ScTemplate{}
+	directed.Triple(ScAddr{Value: 3}, ScType{Value: ScTypeDEdgeVar}, ScType{Value: ScTypeNodeVar})
+	if set, err := g.TemplateSearch(directed, nil); err != nil || set.Len() != 0 {
+		t.Errorf("directed edge is matched in reverse: %v, %v", set, err)
+	}
+}
@@ -0,0 +1,145 @@
+package sc
+
+// GraphDirection represents direction edges are followed in during traversal
+type GraphDirection int
+
+const (
+	GraphBoth GraphDirection = iota
+	GraphOutgoing
+	GraphIncoming
+)
+
+// GraphVisitFunc is called for every visited element with its distance from start.
+// Traversal is stopped when it returns false
+type GraphVisitFunc func(el *GraphElement, depth int) bool
+
+// Out returns edges going from element
+func (g *Graph) Out(addr ScAddr) []*GraphElement {
+	return g.edgesOf(g.out[addr.Value])
+}
+
+// In returns edges going to element
+func (g *Graph) In(addr ScAddr) []*GraphElement {
+	return g.edgesOf(g.in[addr.Value])
+}
+
+func (g *Graph) edgesOf(addrs []int64) []*GraphElement {
+	edges := make([]*GraphElement, len(addrs))
+	for i, addr := range addrs {
+		edges[i] = g.elements[addr]
+	}
+	return edges
+}
+
+// step represents move from element to other end of edge
+type step struct {
+	edge  *GraphElement
+	other ScAddr
+}
+
+// steps returns moves from element along edges in direction
+func (g *Graph) steps(addr ScAddr, direction GraphDirection) []step {
+	var steps []step
+	if direction != GraphIncoming {
+		for _, edge := range g.Out(addr) {
+			steps = append(steps, step{edge: edge, other: edge.Target})
+		}
+	}
+	if direction != GraphOutgoing {
+		for _, edge := range g.In(addr) {
+			steps = append(steps, step{edge: edge, other: edge.Source})
+		}
+	}
+	return steps
+}
+
+// BFS visits elements reachable from start by edges in direction in breadth-first order.
+// Edges are followed but only their ends are visited
+func (g *Graph) BFS(start ScAddr, direction GraphDirection, visit GraphVisitFunc) {
+	el := g.Element(start)
+	if el == nil {
+		return
+	}
+
+	visited := map[int64]bool{start.Value: true}
+	queue := []*GraphElement{el}
+	depths := []int{0}
+	for len(queue) > 0 {
+		current, depth := queue[0], depths[0]
+		queue, depths = queue[1:], depths[1:]
+		if !visit(current, depth) {
+			return
+		}
+
+		for _, s := range g.steps(current.Addr, direction) {
+			if !visited[s.other.Value] {
+				visited[s.other.Value] = true
+				queue = append(queue, g.Element(s.other))
+				depths = append(depths, depth+1)
+			}
+		}
+	}
+}
+
+// DFS visits elements reachable from start by edges in direction in depth-first order
+func (g *Graph) DFS(start ScAddr, direction GraphDirection, visit GraphVisitFunc) {
+	if g.Element(start) == nil {
+		return
+	}
+
+	visited := make(map[int64]bool)
+	var walk func(addr ScAddr, depth int) bool
+	walk = func(addr ScAddr, depth int) bool {
+		visited[addr.Value] = true
+		if !visit(g.Element(addr), depth) {
+			return false
+		}
+		for _, s := range g.steps(addr, direction) {
+			if !visited[s.other.Value] && !walk(s.other, depth+1) {
+				return false
+			}
+		}
+		return true
+	}
+	walk(start, 0)
+}
+
+// ShortestPath returns path with the least number of edges from one element to another.
+// Path alternates elements and edges: from, edge, element, ..., edge, to.
+// Returns false if there is no path
+func (g *Graph) ShortestPath(from, to ScAddr, direction GraphDirection) ([]*GraphElement, bool) {
+	if g.Element(from) == nil || g.Element(to) == nil {
+		return nil, false
+	}
+
+	parents := map[int64]step{from.Value: {}}
+	queue := []ScAddr{from}
+	for len(queue) > 0 && !to.Equal(queue[0]) {
+		current := queue[0]
+		queue = queue[1:]
+		for _, s := range g.steps(current, direction) {
+			if _, visited := parents[s.other.Value]; !visited {
+				parents[s.other.Value] = step{edge: s.edge, other: current}
+				queue = append(queue, s.other)
+			}
+		}
+	}
+	if _, reached := parents[to.Value]; !reached {
+		return nil, false
+	}
+
+	var path []*GraphElement
+	for addr := to; ; {
+		path = append(path, g.Element(addr))
+		parent := parents[addr.Value]
+		if parent.edge == nil {
+			break
+		}
+		path = append(path, parent.edge)
+		addr = parent.other
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, true
+}
@@ -0,0 +1,113 @@
+package sc
+
+import "testing"
+
+// branchedGraph extends test graph with arc 13 from 1 to 5
+func branchedGraph(tb testing.TB) *Graph {
+	tb.Helper()
+
+	g := testGraph(tb)
+	if _, err := g.AddElement(ScAddr{Value: 5}, ScType{Value: ScTypeNodeConst}); err != nil {
+		tb.Fatal(err)
+	}
+	if _, err := g.AddEdge(ScAddr{Value: 13}, ScType{Value: ScTypeArcPosConstPerm}, ScAddr{Value: 1}, ScAddr{Value: 5}); err != nil {
+		tb.Fatal(err)
+	}
+	return g
+}
+
+// traverse returns addrs and depths of visited elements, traversal is stopped after limit elements
+func traverse(walk func(GraphVisitFunc), limit int) ([]int64, []int) {
+	var addrs []int64
+	var depths []int
+	walk(func(el *GraphElement, depth int) bool {
+		addrs = append(addrs, el.Addr.Value)
+		depths = append(depths, depth)
+		return len(addrs) < limit
+	})
+	return addrs, depths
+}
+
+func equalInts[T int | int64](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGraphBFS(t *testing.T) {
+	g := branchedGraph(t)
+
+	for _, test := range []struct {
+		start     int64
+		direction GraphDirection
+		limit     int
+		addrs     []int64
+		depths    []int
+	}{
+		{1, GraphOutgoing, 10, []int64{1, 2, 5, 3}, []int{0, 1, 1, 2}},
+		{3, GraphOutgoing, 10, []int64{3}, []int{0}},
+		{3, GraphIncoming, 10, []int64{3, 2, 1}, []int{0, 1, 2}},
+		{2, GraphBoth, 10, []int64{2, 3, 1, 5}, []int{0, 1, 1, 2}},
+		{1, GraphOutgoing, 2, []int64{1, 2}, []int{0, 1}},
+		// Edges are traversed as elements when traversal starts from them
+		{10, GraphBoth, 10, []int64{10, 4}, []int{0, 1}},
+		{30, GraphBoth, 10, nil, nil},
+	} {
+		addrs, depths := traverse(func(visit GraphVisitFunc) { g.BFS(ScAddr{Value: test.start}, test.direction, visit) }, test.limit)
+		if !equalInts(addrs, test.addrs) || !equalInts(depths, test.depths) {
+			t.Errorf("BFS from %d: expected %v %v, got %v %v", test.start, test.addrs, test.depths, addrs, depths)
+		}
+	}
+}
+
+func TestGraphDFS(t *testing.T) {
+	g := branchedGraph(t)
+
+	for _, test := range []struct {
+		start     int64
+		direction GraphDirection
+		limit     int
+		addrs     []int64
+		depths    []int
+	}{
+		{1, GraphOutgoing, 10, []int64{1, 2, 3, 5}, []int{0, 1, 2, 1}},
+		{3, GraphIncoming, 10, []int64{3, 2, 1}, []int{0, 1, 2}},
+		{5, GraphBoth, 10, []int64{5, 1, 2, 3}, []int{0, 1, 2, 3}},
+		{1, GraphOutgoing, 3, []int64{1, 2, 3}, []int{0, 1, 2}},
+		{30, GraphBoth, 10, nil, nil},
+	} {
+		addrs, depths := traverse(func(visit GraphVisitFunc) { g.DFS(ScAddr{Value: test.start}, test.direction, visit) }, test.limit)
+		if !equalInts(addrs, test.addrs) || !equalInts(depths, test.depths) {
+			t.Errorf("DFS from %d: expected %v %v, got %v %v", test.start, test.addrs, test.depths, addrs, depths)
+		}
+	}
+}
+
+func TestGraphShortestPath(t *testing.T) {
+	g := branchedGraph(t)
+
+	for _, test := range []struct {
+		from, to  int64
+		direction GraphDirection
+		path      []int64
+	}{
+		{1, 3, GraphOutgoing, []int64{1, 10, 2, 11, 3}},
+		{3, 1, GraphIncoming, []int64{3, 11, 2, 10, 1}},
+		{3, 5, GraphBoth, []int64{3, 11, 2, 10, 1, 13, 5}},
+		{1, 1, GraphBoth, []int64{1}},
+		{3, 1, GraphOutgoing, nil},
+		{1, 4, GraphBoth, nil},
+		{1, 30, GraphBoth, nil},
+	} {
+		path, found := g.ShortestPath(ScAddr{Value: test.from}, ScAddr{Value: test.to}, test.direction)
+		if found != (test.path != nil) || !equalInts(graphAddrs(path), test.path) {
+			t.Errorf("path from %d to %d: expected %v, got %v, %v", test.from, test.to, test.path, graphAddrs(path), found)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package sc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// System identifiers of keynodes used for identification of elements
+const (
+	KeynodeNrelSystemIdentifier = "nrel_system_identifier"
+	KeynodeNrelMainIdtf         = "nrel_main_idtf"
+	KeynodeLangRu               = "lang_ru"
+	KeynodeLangEn               = "lang_en"
+)
+
+// identifierTemplate returns template of addr => rel: _link; lang -> _link. Lang may be nil
+func identifierTemplate(addr interface{}, rel, lang ScAddr, link interface{}) *ScTemplate {
+	template := &ScTemplate{}
+	template.TripleWithRelation(
+		addr,
+		[]interface{}{ScType{Value: ScTypeDEdgeVar}, "_edge"},
+		link,
+		[]interface{}{ScType{Value: ScTypeArcPosVarPerm}, "_rel_arc"},
+		rel,
+	)
+	if lang.IsValid() {
+		template.Triple(lang, []interface{}{ScType{Value: ScTypeArcPosVarPerm}, "_lang_arc"}, "_link")
+	}
+	return template
+}
+
+// resolveLang returns address of language passed as ScAddr or system identifier. Nil means any language
+func (c *ScClient) resolveLang(lang interface{}) (ScAddr, error) {
+	if lang == nil {
+		return ScAddr{}, nil
+	}
+	return c.resolveElement(lang)
+}
+
+// GetSystemIdtf returns system identifier of element
+func (c *ScClient) GetSystemIdtf(addr ScAddr) (string, error) {
+	idtfs, err := c.GetSystemIdtfs([]ScAddr{addr})
+	if err != nil {
+		return "", err
+	}
+	if idtfs[0] == "" {
+		return "", CommonError(ErrElementNotFound, fmt.Sprintf("system identifier of %v", addr))
+	}
+	return idtfs[0], nil
+}
+
+// GetSystemIdtfs returns system identifiers of elements, empty string for elements without one.
+// Searches are sent at once and contents are fetched with one request
+func (c *ScClient) GetSystemIdtfs(addrs []ScAddr) ([]string, error) {
+	rel, err := c.resolveElement(KeynodeNrelSystemIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	return c.getIdtfs(addrs, rel, ScAddr{})
+}
+
+// GetMainIdtf returns main identifier of element in language passed as ScAddr or system identifier.
+// Nil language means any language
+func (c *ScClient) GetMainIdtf(addr ScAddr, lang interface{}) (string, error) {
+	idtfs, err := c.GetMainIdtfs([]ScAddr{addr}, lang)
+	if err != nil {
+		return "", err
+	}
+	if idtfs[0] == "" {
+		return "", CommonError(ErrElementNotFound, fmt.Sprintf("main identifier of %v", addr))
+	}
+	return idtfs[0], nil
+}
+
+// GetMainIdtfs returns main identifiers of elements in language, empty string for elements without one
+func (c *ScClient) GetMainIdtfs(addrs []ScAddr, lang interface{}) ([]string, error) {
+	rel, err := c.resolveElement(KeynodeNrelMainIdtf)
+	if err != nil {
+		return nil, err
+	}
+	langAddr, err := c.resolveLang(lang)
+	if err != nil {
+		return nil, err
+	}
+	return c.getIdtfs(addrs, rel, langAddr)
+}
+
+// getIdtfs finds identifier links of elements and reads their contents
+func (c *ScClient) getIdtfs(addrs []ScAddr, rel, lang ScAddr) ([]string, error) {
+	links, err := c.findIdtfLinks(addrs, rel, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []ScAddr
+	for _, link := range links {
+		if link.IsValid() {
+			found = append(found, link)
+		}
+	}
+
+	idtfs := make([]string, len(addrs))
+	if len(found) == 0 {
+		return idtfs, nil
+	}
+
+	contents, err := c.getLinkContents(found)
+	if err != nil {
+		return nil, err
+	}
+
+	next := 0
+	for i, link := range links {
+		if !link.IsValid() {
+			continue
+		}
+		if next < len(contents) && contents[next] != nil {
+			idtfs[i] = fmt.Sprint(contents[next].Data)
+		}
+		next++
+	}
+	return idtfs, nil
+}
+
+// findIdtfLinks returns identifier link of every element, invalid addr for elements without one
+func (c *ScClient) findIdtfLinks(addrs []ScAddr, rel, lang ScAddr) ([]ScAddr, error) {
+	requests := make([]<-chan *ScTemplateResultSet, len(addrs))
+	for i, addr := range addrs {
+		request, err := c.sendTemplateSearch(identifierTemplate(addr, rel, lang, []interface{}{ScType{Value: ScTypeLinkVar}, "_link"}), nil)
+		if err != nil {
+			return nil, err
+		}
+		requests[i] = request
+	}
+
+	links := make([]ScAddr, len(addrs))
+	for i, request := range requests {
+		set, err := waitTemplateSearch(request)
+		if err != nil {
+			return nil, err
+		}
+		if set.Len() > 0 {
+			links[i] = set.Row(0).Get("_link")
+		}
+	}
+	return links, nil
+}
+
+// SetSystemIdtf sets system identifier of element
+func (c *ScClient) SetSystemIdtf(addr ScAddr, idtf string) error {
+	rel, err := c.resolveElement(KeynodeNrelSystemIdentifier)
+	if err != nil {
+		return err
+	}
+	return c.setIdtf(addr, rel, ScAddr{}, idtf)
+}
+
+// SetMainIdtf sets main identifier of element in language passed as ScAddr or system identifier
+func (c *ScClient) SetMainIdtf(addr ScAddr, lang interface{}, text string) error {
+	rel, err := c.resolveElement(KeynodeNrelMainIdtf)
+	if err != nil {
+		return err
+	}
+	langAddr, err := c.resolveElement(lang)
+	if err != nil {
+		return err
+	}
+	return c.setIdtf(addr, rel, langAddr, text)
+}
+
+// setIdtf updates content of existing identifier link or creates new one
+func (c *ScClient) setIdtf(addr, rel, lang ScAddr, text string) error {
+	links, err := c.findIdtfLinks([]ScAddr{addr}, rel, lang)
+	if err != nil {
+		return err
+	}
+
+	if link := links[0]; link.IsValid() {
+		results, err := c.SetLinkContents([]ScLinkContent{{Data: text, Type: ScLinkContentString, Addr: &link}})
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 || !results[0] {
+			return errors.New("failed to set identifier content")
+		}
+		return nil
+	}
+
+	construction := &ScConstruction{}
+	if err := construction.CreateLink(ScType{Value: ScTypeLinkConst}, ScLinkContent{Data: text, Type: ScLinkContentString}, "link"); err != nil {
+		return err
+	}
+	if err := construction.CreateEdge(ScType{Value: ScTypeDEdgeConst}, addr, "link", "edge"); err != nil {
+		return err
+	}
+	if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, rel, "edge", ""); err != nil {
+		return err
+	}
+	if lang.IsValid() {
+		if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, lang, "link", ""); err != nil {
+			return err
+		}
+	}
+
+	_, err = c.CreateElements(construction)
+	return err
+}
+
+// FindByIdtf returns elements having main identifier text in language. Nil language means any language
+func (c *ScClient) FindByIdtf(text string, lang interface{}) ([]ScAddr, error) {
+	rel, err := c.resolveElement(KeynodeNrelMainIdtf)
+	if err != nil {
+		return nil, err
+	}
+	langAddr, err := c.resolveLang(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := c.FindLinksByContents([]interface{}{text})
+	if err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	requests := make([]<-chan *ScTemplateResultSet, len(links[0]))
+	for i, link := range links[0] {
+		request, err := c.sendTemplateSearch(identifierTemplate([]interface{}{ScType{}, "_el"}, rel, langAddr, []interface{}{link, "_link"}), nil)
+		if err != nil {
+			return nil, err
+		}
+		requests[i] = request
+	}
+
+	seen := make(map[int64]bool)
+	var elements []ScAddr
+	for _, request := range requests {
+		set, err := waitTemplateSearch(request)
+		if err != nil {
+			return nil, err
+		}
+		for _, el := range set.Distinct("_el") {
+			if !seen[el.Value] {
+				seen[el.Value] = true
+				elements = append(elements, el)
+			}
+		}
+	}
+	return elements, nil
+}
@@ -0,0 +1,109 @@
+package sc_test
+
+import (
+	"strings"
+	"testing"
+
+	sc "github.com/temapriemnik/go-sc-client"
+	"github.com/temapriemnik/go-sc-client/agenttest"
+)
+
+func TestSystemIdtf(t *testing.T) {
+	kit := agenttest.New(t)
+	addrs := kit.LoadSCs(`
+		..named <- concept_item;;
+		..unnamed <- concept_item;;
+	`)
+
+	if err := kit.Client.SetSystemIdtf(addrs["..named"], "first_name"); err != nil {
+		t.Fatal(err)
+	}
+	if idtf, err := kit.Client.GetSystemIdtf(addrs["..named"]); err != nil || idtf != "first_name" {
+		t.Errorf("unexpected identifier %q, %v", idtf, err)
+	}
+
+	// Existing identifier link is updated
+	if err := kit.Client.SetSystemIdtf(addrs["..named"], "second_name"); err != nil {
+		t.Fatal(err)
+	}
+	idtfs, err := kit.Client.GetSystemIdtfs([]sc.ScAddr{addrs["..unnamed"], addrs["..named"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idtfs) != 2 || idtfs[0] != "" || idtfs[1] != "second_name" {
+		t.Errorf("unexpected identifiers %q", idtfs)
+	}
+	template := &sc.ScTemplate{}
+	template.TripleWithRelation(addrs["..named"], sc.ScType{Value: sc.ScTypeDEdgeVar}, sc.ScType{Value: sc.ScTypeLinkVar},
+		sc.ScType{Value: sc.ScTypeArcPosVarPerm}, kit.Keynode(sc.KeynodeNrelSystemIdentifier, sc.ScTypeNodeConstNoRole))
+	if results := kit.AssertTemplateExists(template, nil); len(results) != 1 {
+		t.Errorf("expected one identifier link, got %d", len(results))
+	}
+
+	if _, err := kit.Client.GetSystemIdtf(addrs["..unnamed"]); err == nil || !strings.HasPrefix(err.Error(), sc.ErrElementNotFound.Error()) {
+		t.Errorf("expected element not found error, got %v", err)
+	}
+}
+
+func TestMainIdtf(t *testing.T) {
+	kit := agenttest.New(t)
+	kit.Keynode(sc.KeynodeNrelMainIdtf, sc.ScTypeNodeConstNoRole)
+	ru := kit.Keynode(sc.KeynodeLangRu, sc.ScTypeNodeConstClass)
+	kit.Keynode(sc.KeynodeLangEn, sc.ScTypeNodeConstClass)
+	addrs := kit.LoadSCs(`
+		..city <- concept_city;;
+		..other_city <- concept_city;;
+	`)
+
+	if err := kit.Client.SetMainIdtf(addrs["..city"], sc.KeynodeLangEn, "Minsk"); err != nil {
+		t.Fatal(err)
+	}
+	if err := kit.Client.SetMainIdtf(addrs["..city"], ru, "Минск"); err != nil {
+		t.Fatal(err)
+	}
+	if err := kit.Client.SetMainIdtf(addrs["..other_city"], ru, "Minsk"); err != nil {
+		t.Fatal(err)
+	}
+
+	if idtf, err := kit.Client.GetMainIdtf(addrs["..city"], sc.KeynodeLangEn); err != nil || idtf != "Minsk" {
+		t.Errorf("unexpected english identifier %q, %v", idtf, err)
+	}
+	if idtf, err := kit.Client.GetMainIdtf(addrs["..city"], ru); err != nil || idtf != "Минск" {
+		t.Errorf("unexpected russian identifier %q, %v", idtf, err)
+	}
+	if idtf, err := kit.Client.GetMainIdtf(addrs["..city"], nil); err != nil || idtf == "" {
+		t.Errorf("identifier in any language is not found: %v", err)
+	}
+	idtfs, err := kit.Client.GetMainIdtfs([]sc.ScAddr{addrs["..other_city"], addrs["..city"]}, sc.KeynodeLangEn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idtfs) != 2 || idtfs[0] != "" || idtfs[1] != "Minsk" {
+		t.Errorf("unexpected identifiers %q", idtfs)
+	}
+	if _, err := kit.Client.GetMainIdtf(addrs["..other_city"], sc.KeynodeLangEn); err == nil || !strings.HasPrefix(err.Error(), sc.ErrElementNotFound.Error()) {
+		t.Errorf("expected element not found error, got %v", err)
+	}
+	if err := kit.Client.SetMainIdtf(addrs["..city"], nil, "Minsk"); err == nil {
+		t.Error("identifier is set without language")
+	}
+
+	// Language filters elements found by text
+	found, err := kit.Client.FindByIdtf("Minsk", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 {
+		t.Errorf("expected both cities, got %v", found)
+	}
+	found, err = kit.Client.FindByIdtf("Minsk", sc.KeynodeLangEn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || !found[0].Equal(addrs["..city"]) {
+		t.Errorf("unexpected cities %v", found)
+	}
+	if found, err := kit.Client.FindByIdtf("Brest", nil); err != nil || len(found) != 0 {
+		t.Errorf("unexpected cities %v, %v", found, err)
+	}
+}
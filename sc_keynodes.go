@@ -0,0 +1,296 @@
+package sc
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// scTypeNames maps names of type constants used in keynode tags to types
+var scTypeNames = map[string]int{
+	"ScTypeNode":            ScTypeNode,
+	"ScTypeLink":            ScTypeLink,
+	"ScTypeNodeConst":       ScTypeNodeConst,
+	"ScTypeNodeVar":         ScTypeNodeVar,
+	"ScTypeNodeConstClass":  ScTypeNodeConstClass,
+	"ScTypeNodeVarClass":    ScTypeNodeVarClass,
+	"ScTypeNodeConstRole":   ScTypeNodeConstRole,
+	"ScTypeNodeVarRole":     ScTypeNodeVarRole,
+	"ScTypeNodeConstNoRole": ScTypeNodeConstNoRole,
+	"ScTypeNodeVarNoRole":   ScTypeNodeVarNoRole,
+	"ScTypeNodeConstTuple":  ScTypeNodeConstTuple,
+	"ScTypeNodeVarTuple":    ScTypeNodeVarTuple,
+	"ScTypeNodeConstStruct": ScTypeNodeConstStruct,
+	"ScTypeNodeVarStruct":   ScTypeNodeVarStruct,
+	"ScTypeLinkConst":       ScTypeLinkConst,
+	"ScTypeLinkVar":         ScTypeLinkVar,
+}
+
+// MissingKeynodesError is returned when keynodes are not found in knowledge base
+type MissingKeynodesError struct {
+	Idtfs []string
+}
+
+func (e *MissingKeynodesError) Error() string {
+	return fmt.Sprintf("%s: keynodes %s", ErrElementNotFound.Error(), strings.Join(e.Idtfs, ", "))
+}
+
+// Is allows to check error with errors.Is(err, ErrElementNotFound)
+func (e *MissingKeynodesError) Is(target error) bool {
+	return target == ErrElementNotFound
+}
+
+// keynodeRegistry caches resolved keynodes of client and keeps keynodes to resolve after reconnection
+type keynodeRegistry struct {
+	mu      sync.Mutex
+	cache   map[string]ScAddr
+	targets []keynodeTarget
+
+	commonOnce sync.Once
+	common     *Keynodes[CommonKeynodes]
+}
+
+// keynodeTarget represents registered keynodes resolved again after reconnection
+type keynodeTarget interface {
+	resolve() error
+}
+
+func (r *keynodeRegistry) register(target keynodeTarget) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.targets = append(r.targets, target)
+}
+
+// Keynodes holds keynodes declared by struct T. Struct is resolved again into new value when
+// client reconnects, so values returned by Get are never changed and are safe to read
+type Keynodes[T any] struct {
+	client *ScClient
+	value  atomic.Pointer[T]
+}
+
+// RegisterKeynodes resolves keynodes declared by fields of struct T (see ResolveKeynodesInto)
+// and resolves them again when client reconnects
+func RegisterKeynodes[T any](c *ScClient) (*Keynodes[T], error) {
+	k := &Keynodes[T]{client: c}
+	if err := k.resolve(); err != nil {
+		return nil, err
+	}
+	c.keynodes.register(k)
+	return k, nil
+}
+
+// Get returns last resolved keynodes
+func (k *Keynodes[T]) Get() T {
+	if value := k.value.Load(); value != nil {
+		return *value
+	}
+	var empty T
+	return empty
+}
+
+func (k *Keynodes[T]) resolve() error {
+	value := new(T)
+	if err := k.client.ResolveKeynodesInto(value); err != nil {
+		return err
+	}
+	k.value.Store(value)
+	return nil
+}
+
+// keynodeField represents struct field declaring keynode
+type keynodeField struct {
+	index int
+	idtf  string
+	t     ScType
+}
+
+// keynodeFields returns fields of struct type tagged with `sc:"idtf[,ScTypeName]"`
+func keynodeFields(t reflect.Type) ([]keynodeField, error) {
+	var fields []keynodeField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, tagged := field.Tag.Lookup("sc")
+		if !tagged || tag == "" || tag == "-" || !field.IsExported() {
+			continue
+		}
+		if field.Type != scAddrType {
+			return nil, CommonError(ErrInvalidType, fmt.Sprintf("keynode field %s should be ScAddr", field.Name))
+		}
+
+		parts := strings.SplitN(tag, ",", 2)
+		kf := keynodeField{index: i, idtf: strings.TrimSpace(parts[0])}
+		if len(parts) == 2 {
+			name := strings.TrimSpace(parts[1])
+			value, exists := scTypeNames[name]
+			if !exists {
+				return nil, CommonError(ErrInvalidType, fmt.Sprintf("unknown type %q of keynode %q", name, kf.idtf))
+			}
+			kf.t = ScType{Value: value}
+		}
+		fields = append(fields, kf)
+	}
+	return fields, nil
+}
+
+// ResolveKeynodesInto resolves keynodes declared by ScAddr fields of struct with
+// `sc:"idtf"` (find) or `sc:"idtf,ScTypeNodeConstClass"` (find or create) tags.
+// All keynodes missing in cache are resolved with one request. Struct isn't updated
+// after reconnection, use RegisterKeynodes to keep keynodes up to date
+func (c *ScClient) ResolveKeynodesInto(dst interface{}) error {
+	v, err := structValue(dst)
+	if err != nil {
+		return err
+	}
+
+	fields, err := keynodeFields(v.Type())
+	if err != nil {
+		return err
+	}
+
+	types := make(map[string]ScType, len(fields))
+	for _, field := range fields {
+		if prev, exists := types[field.idtf]; !exists || !prev.IsValid() {
+			types[field.idtf] = field.t
+		}
+	}
+
+	addrs, err := c.resolveCachedKeynodes(types)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, field := range fields {
+		addr := addrs[field.idtf]
+		if !addr.IsValid() {
+			missing = append(missing, field.idtf)
+			continue
+		}
+		v.Field(field.index).Set(reflect.ValueOf(addr))
+	}
+
+	if len(missing) > 0 {
+		return &MissingKeynodesError{Idtfs: missing}
+	}
+	return nil
+}
+
+// resolveCachedKeynodes resolves keynodes missing in cache with one request
+func (c *ScClient) resolveCachedKeynodes(types map[string]ScType) (map[string]ScAddr, error) {
+	result := make(map[string]ScAddr, len(types))
+	var requests []KeynodeRequest
+
+	c.keynodes.mu.Lock()
+	for idtf, t := range types {
+		if addr, cached := c.keynodes.cache[idtf]; cached {
+			result[idtf] = addr
+		} else {
+			requests = append(requests, KeynodeRequest{Idtf: idtf, Type: t})
+		}
+	}
+	c.keynodes.mu.Unlock()
+
+	if len(requests) == 0 {
+		return result, nil
+	}
+
+	resolved, err := c.ResolveKeynodesOrdered(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	c.keynodes.mu.Lock()
+	defer c.keynodes.mu.Unlock()
+	if c.keynodes.cache == nil {
+		c.keynodes.cache = make(map[string]ScAddr)
+	}
+	for _, keynode := range resolved {
+		result[keynode.Idtf] = keynode.Addr
+		if keynode.Found {
+			c.keynodes.cache[keynode.Idtf] = keynode.Addr
+		}
+	}
+	return result, nil
+}
+
+// Keynode returns cached address of element with system identifier
+func (c *ScClient) Keynode(idtf string) (ScAddr, error) {
+	addrs, err := c.resolveCachedKeynodes(map[string]ScType{idtf: {}})
+	if err != nil {
+		return ScAddr{}, err
+	}
+	if addr := addrs[idtf]; addr.IsValid() {
+		return addr, nil
+	}
+	return ScAddr{}, &MissingKeynodesError{Idtfs: []string{idtf}}
+}
+
+// reresolveKeynodes clears keynodes cache and resolves registered keynodes again
+func (c *ScClient) reresolveKeynodes() {
+	c.keynodes.mu.Lock()
+	c.keynodes.cache = nil
+	targets := append([]keynodeTarget(nil), c.keynodes.targets...)
+	c.keynodes.mu.Unlock()
+
+	for _, target := range targets {
+		if err := target.resolve(); err != nil {
+			log.Printf("Failed to resolve keynodes after reconnection: %v", err)
+		}
+	}
+}
+
+// CommonKeynodes represents standard OSTIS keynodes
+type CommonKeynodes struct {
+	NrelSystemIdentifier ScAddr `sc:"nrel_system_identifier,ScTypeNodeConstNoRole"`
+	NrelMainIdtf         ScAddr `sc:"nrel_main_idtf,ScTypeNodeConstNoRole"`
+	NrelIdtf             ScAddr `sc:"nrel_idtf,ScTypeNodeConstNoRole"`
+	NrelBasicSequence    ScAddr `sc:"nrel_basic_sequence,ScTypeNodeConstNoRole"`
+	NrelResult           ScAddr `sc:"nrel_result,ScTypeNodeConstNoRole"`
+	NrelInclusion        ScAddr `sc:"nrel_inclusion,ScTypeNodeConstNoRole"`
+	RrelKeyScElement     ScAddr `sc:"rrel_key_sc_element,ScTypeNodeConstRole"`
+	Rrel1                ScAddr `sc:"rrel_1,ScTypeNodeConstRole"`
+	Rrel2                ScAddr `sc:"rrel_2,ScTypeNodeConstRole"`
+	Rrel3                ScAddr `sc:"rrel_3,ScTypeNodeConstRole"`
+	LangRu               ScAddr `sc:"lang_ru,ScTypeNodeConstClass"`
+	LangEn               ScAddr `sc:"lang_en,ScTypeNodeConstClass"`
+
+	Action                       ScAddr `sc:"action,ScTypeNodeConstClass"`
+	ActionInitiated              ScAddr `sc:"action_initiated,ScTypeNodeConstClass"`
+	ActionFinished               ScAddr `sc:"action_finished,ScTypeNodeConstClass"`
+	ActionFinishedSuccessfully   ScAddr `sc:"action_finished_successfully,ScTypeNodeConstClass"`
+	ActionFinishedUnsuccessfully ScAddr `sc:"action_finished_unsuccessfully,ScTypeNodeConstClass"`
+	ActionFinishedWithError      ScAddr `sc:"action_finished_with_error,ScTypeNodeConstClass"`
+}
+
+// CommonKeynodes resolves standard OSTIS keynodes. Result is cached by client and resolved
+// again after reconnection, returned copy is not changed
+func (c *ScClient) CommonKeynodes() (*CommonKeynodes, error) {
+	c.keynodes.commonOnce.Do(func() {
+		c.keynodes.common = &Keynodes[CommonKeynodes]{client: c}
+		c.keynodes.register(c.keynodes.common)
+	})
+
+	if c.keynodes.common.value.Load() == nil {
+		if err := c.keynodes.common.resolve(); err != nil {
+			return nil, err
+		}
+	}
+	common := c.keynodes.common.Get()
+	return &common, nil
+}
+
+// KeynodeRequest represents keynode to resolve. Invalid type means find without creation
+type KeynodeRequest struct {
+	Idtf string
+	Type ScType
+}
+
+// KeynodeResult represents resolved keynode
+type KeynodeResult struct {
+	Idtf  string
+	Addr  ScAddr
+	Found bool
+}
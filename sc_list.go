@@ -0,0 +1,702 @@
+package sc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+)
+
+// ScListOrdering represents the way order of list members is stored in knowledge base
+type ScListOrdering int
+
+const (
+	// ScListOrderByRoles marks membership arcs with rrel_1, rrel_2, ...
+	ScListOrderByRoles ScListOrdering = iota
+	// ScListOrderBySequence links membership arcs with nrel_basic_sequence
+	ScListOrderBySequence
+)
+
+// ScList represents ordered SC set
+type ScList struct {
+	Client   *ScClient
+	Addr     ScAddr
+	Ordering ScListOrdering
+	OnChange func([]ScAddr)
+
+	mu        sync.Mutex
+	roles     map[int]ScAddr
+	items     []ScAddr
+	listeners []*ScEventListener
+	// arcListeners holds listeners of position changes by membership arcs, it is nil if list isn't watched
+	arcListeners map[int64][]*ScEventListener
+	// refreshMu serializes refreshes of watched list
+	refreshMu sync.Mutex
+}
+
+// listMember represents list element with arcs storing its position
+type listMember struct {
+	arc   ScAddr
+	item  ScAddr
+	links []ScAddr
+	// position is stored 1-based position of member ordered by roles, 0 if it is unknown
+	position int
+	// next holds membership arcs targeted by sequence links, it is aligned with links
+	next []ScAddr
+}
+
+// NewScList creates new SC list
+func NewScList(client *ScClient, addr ScAddr, ordering ScListOrdering) (*ScList, error) {
+	if !addr.IsValid() {
+		return nil, fmt.Errorf("invalid addr of list: %v", addr)
+	}
+
+	return &ScList{
+		Client:   client,
+		Addr:     addr,
+		Ordering: ordering,
+		roles:    make(map[int]ScAddr),
+	}, nil
+}
+
+// resolveRoles returns rrel_1 ... rrel_n keynodes resolved with one request.
+// Missing keynodes are created if create is set, otherwise they are absent in result
+func (l *ScList) resolveRoles(n int, create bool) (map[int]ScAddr, error) {
+	t := ScType{}
+	if create {
+		t = ScType{Value: ScTypeNodeConstRole}
+	}
+
+	roles := make(map[int]ScAddr, n)
+	missing := make(map[string]ScType)
+	l.mu.Lock()
+	for i := 1; i <= n; i++ {
+		if addr, cached := l.roles[i]; cached {
+			roles[i] = addr
+		} else {
+			missing[fmt.Sprintf("rrel_%d", i)] = t
+		}
+	}
+	l.mu.Unlock()
+
+	if len(missing) == 0 {
+		return roles, nil
+	}
+	keynodes, err := l.Client.ResolveKeynodes(missing)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := 1; i <= n; i++ {
+		if addr := keynodes[fmt.Sprintf("rrel_%d", i)]; addr.IsValid() {
+			l.roles[i] = addr
+			roles[i] = addr
+		}
+	}
+	return roles, nil
+}
+
+func (l *ScList) sequenceRelation(create bool) (ScAddr, error) {
+	t := ScType{}
+	if create {
+		t = ScType{Value: ScTypeNodeConstNoRole}
+	}
+
+	keynodes, err := l.Client.ResolveKeynodes(map[string]ScType{"nrel_basic_sequence": t})
+	if err != nil {
+		return ScAddr{}, err
+	}
+	return keynodes["nrel_basic_sequence"], nil
+}
+
+// membershipTemplate returns template of list membership arcs
+func (l *ScList) membershipTemplate() *ScTemplate {
+	template := &ScTemplate{}
+	template.Triple(
+		l.Addr,
+		[]interface{}{ScType{Value: ScTypeArcPosVarPerm}, "_arc"},
+		[]interface{}{ScType{Value: 0}, "_item"},
+	)
+	return template
+}
+
+// members searches list members in order. Members without position are returned separately
+func (l *ScList) members() ([]listMember, []listMember, error) {
+	if l.Ordering == ScListOrderBySequence {
+		members, err := l.membersBySequence()
+		return members, nil, err
+	}
+	return l.membersByRoles()
+}
+
+// orderedMembers returns members in order followed by members without position,
+// so rewriting list assigns positions to them
+func (l *ScList) orderedMembers() ([]listMember, error) {
+	members, unpositioned, err := l.members()
+	if err != nil {
+		return nil, err
+	}
+	return append(members, unpositioned...), nil
+}
+
+// membersByRoles searches members ordered by rrel_i roles of their arcs. Roles out of range
+// [1, number of members] don't give position, all role arcs of member are kept to be rewritten
+func (l *ScList) membersByRoles() ([]listMember, []listMember, error) {
+	roleTemplate := l.membershipTemplate()
+	roleTemplate.Triple(
+		[]interface{}{ScType{Value: ScTypeNodeVarRole}, "_role"},
+		[]interface{}{ScType{Value: ScTypeArcPosVarPerm}, "_role_arc"},
+		"_arc",
+	)
+
+	membershipSearch, err := l.Client.sendTemplateSearch(l.membershipTemplate(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	roleSearch, err := l.Client.sendTemplateSearch(roleTemplate, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	memberships, err := waitTemplateSearch(membershipSearch)
+	if err != nil {
+		return nil, nil, err
+	}
+	roleResults, err := waitTemplateSearch(roleSearch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	roles, err := l.resolveRoles(memberships.Len(), false)
+	if err != nil {
+		return nil, nil, err
+	}
+	positions := make(map[int64]int, len(roles))
+	for i, role := range roles {
+		positions[role.Value] = i
+	}
+
+	members := make(map[int64]*listMember, memberships.Len())
+	arcs := make([]ScAddr, 0, memberships.Len())
+	for _, result := range memberships.Rows() {
+		arc := result.Get("_arc")
+		members[arc.Value] = &listMember{arc: arc, item: result.Get("_item")}
+		arcs = append(arcs, arc)
+	}
+
+	for _, result := range roleResults.Rows() {
+		member, exists := members[result.Get("_arc").Value]
+		if !exists {
+			continue
+		}
+		member.links = append(member.links, result.Get("_role_arc"))
+		position, inRange := positions[result.Get("_role").Value]
+		if inRange && (member.position == 0 || position < member.position) {
+			member.position = position
+		}
+	}
+
+	var ordered, unpositioned []listMember
+	for _, arc := range arcs {
+		if members[arc.Value].position == 0 {
+			unpositioned = append(unpositioned, *members[arc.Value])
+		} else {
+			ordered = append(ordered, *members[arc.Value])
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].position < ordered[j].position })
+	return ordered, unpositioned, nil
+}
+
+func (l *ScList) membersBySequence() ([]listMember, error) {
+	relation, err := l.sequenceRelation(false)
+	if err != nil {
+		return nil, err
+	}
+
+	membershipSearch, err := l.Client.sendTemplateSearch(l.membershipTemplate(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var sequenceSearch <-chan *ScTemplateResultSet
+	if relation.IsValid() {
+		// Both ends of sequence arc should be membership arcs of this list
+		sequence := &ScTemplate{}
+		sequence.Triple(
+			l.Addr,
+			[]interface{}{ScType{Value: ScTypeArcPosVarPerm}, "_prev"},
+			[]interface{}{ScType{Value: 0}, "_prev_item"},
+		)
+		sequence.Triple(
+			l.Addr,
+			[]interface{}{ScType{Value: ScTypeArcPosVarPerm}, "_next"},
+			[]interface{}{ScType{Value: 0}, "_next_item"},
+		)
+		sequence.TripleWithRelation(
+			"_prev",
+			[]interface{}{ScType{Value: ScTypeDEdgeVar}, "_seq"},
+			"_next",
+			[]interface{}{ScType{Value: ScTypeArcPosVarPerm}, "_seq_arc"},
+			relation,
+		)
+		if sequenceSearch, err = l.Client.sendTemplateSearch(sequence, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	results, err := waitTemplateSearch(membershipSearch)
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[int64]*listMember, results.Len())
+	var arcs []ScAddr
+	for _, result := range results.Rows() {
+		arc := result.Get("_arc")
+		members[arc.Value] = &listMember{arc: arc, item: result.Get("_item")}
+		arcs = append(arcs, arc)
+	}
+
+	if sequenceSearch == nil {
+		return l.unordered(arcs, members), nil
+	}
+	links, err := waitTemplateSearch(sequenceSearch)
+	if err != nil {
+		return nil, err
+	}
+
+	next := make(map[int64]ScAddr)
+	hasPrev := make(map[int64]bool)
+	for _, link := range links.Rows() {
+		prev, nextArc := link.Get("_prev"), link.Get("_next")
+		prevMember, prevExists := members[prev.Value]
+		if _, nextExists := members[nextArc.Value]; !prevExists || !nextExists {
+			continue
+		}
+		next[prev.Value] = nextArc
+		hasPrev[nextArc.Value] = true
+		prevMember.links = append(prevMember.links, link.Get("_seq"))
+		prevMember.next = append(prevMember.next, nextArc)
+	}
+
+	var ordered []listMember
+	visited := make(map[int64]bool)
+	for _, arc := range arcs {
+		if hasPrev[arc.Value] {
+			continue
+		}
+		for current := arc; current.IsValid() && !visited[current.Value]; current = next[current.Value] {
+			visited[current.Value] = true
+			ordered = append(ordered, *members[current.Value])
+		}
+	}
+	// Members of cycles have previous members, they are appended in order of search results
+	for _, arc := range arcs {
+		if !visited[arc.Value] {
+			for current := arc; current.IsValid() && !visited[current.Value]; current = next[current.Value] {
+				visited[current.Value] = true
+				ordered = append(ordered, *members[current.Value])
+			}
+		}
+	}
+	return ordered, nil
+}
+
+// unordered returns members in order of search results
+func (l *ScList) unordered(arcs []ScAddr, members map[int64]*listMember) []listMember {
+	ordered := make([]listMember, len(arcs))
+	for i, arc := range arcs {
+		ordered[i] = *members[arc.Value]
+	}
+	return ordered
+}
+
+// Items returns list members in order. Returns error if some members have no position,
+// modifying list assigns positions after ordered members to them
+func (l *ScList) Items() ([]ScAddr, error) {
+	members, unpositioned, err := l.members()
+	if err != nil {
+		return nil, err
+	}
+	if len(unpositioned) > 0 {
+		return nil, l.unpositionedError(members, unpositioned)
+	}
+
+	items := make([]ScAddr, len(members))
+	for i, member := range members {
+		items[i] = member.item
+	}
+	return items, nil
+}
+
+// unpositionedError reports members without position
+func (l *ScList) unpositionedError(members, unpositioned []listMember) error {
+	items := make([]ScAddr, len(unpositioned))
+	for i, member := range unpositioned {
+		items[i] = member.item
+	}
+	return CommonError(ErrInvalidState, fmt.Sprintf("list %v has members without position in [1, %d]: %v",
+		l.Addr, len(members)+len(unpositioned), items))
+}
+
+// Append adds item to the end of list
+func (l *ScList) Append(addr ScAddr) error {
+	members, err := l.orderedMembers()
+	if err != nil {
+		return err
+	}
+	return l.insert(members, len(members), addr)
+}
+
+// Insert adds item at position i moving next items forward
+func (l *ScList) Insert(i int, addr ScAddr) error {
+	members, err := l.orderedMembers()
+	if err != nil {
+		return err
+	}
+	return l.insert(members, i, addr)
+}
+
+func (l *ScList) insert(members []listMember, i int, addr ScAddr) error {
+	if !addr.IsValid() {
+		return InvalidValueError(fmt.Sprintf("invalid addr of list item: %v", addr))
+	}
+	if i < 0 || i > len(members) {
+		return InvalidValueError(fmt.Sprintf("index %d is out of list bounds [0, %d]", i, len(members)))
+	}
+
+	ordered := make([]listMember, 0, len(members)+1)
+	ordered = append(ordered, members[:i]...)
+	ordered = append(ordered, listMember{item: addr})
+	ordered = append(ordered, members[i:]...)
+	return l.rewrite(members, ordered, nil)
+}
+
+// Remove deletes membership of item at position i
+func (l *ScList) Remove(i int) error {
+	members, err := l.orderedMembers()
+	if err != nil {
+		return err
+	}
+	if i < 0 || i >= len(members) {
+		return InvalidValueError(fmt.Sprintf("index %d is out of list bounds [0, %d)", i, len(members)))
+	}
+
+	ordered := make([]listMember, 0, len(members)-1)
+	ordered = append(ordered, members[:i]...)
+	ordered = append(ordered, members[i+1:]...)
+	return l.rewrite(members, ordered, []ScAddr{members[i].arc})
+}
+
+// Move moves item from position from to position to
+func (l *ScList) Move(from, to int) error {
+	members, err := l.orderedMembers()
+	if err != nil {
+		return err
+	}
+	if from < 0 || from >= len(members) || to < 0 || to >= len(members) {
+		return InvalidValueError(fmt.Sprintf("indices %d and %d should be in list bounds [0, %d)", from, to, len(members)))
+	}
+	if from == to {
+		return nil
+	}
+
+	moved := members[from]
+	ordered := make([]listMember, 0, len(members))
+	ordered = append(ordered, members[:from]...)
+	ordered = append(ordered, members[from+1:]...)
+	ordered = append(ordered[:to], append([]listMember{moved}, ordered[to:]...)...)
+	return l.rewrite(members, ordered, nil)
+}
+
+// rewrite stores new order of members and deletes removed membership arcs with one request.
+// Members without arc are created, only members which changed position are rewritten
+func (l *ScList) rewrite(previous, ordered []listMember, removed []ScAddr) error {
+	if l.Ordering == ScListOrderBySequence {
+		return l.rewriteSequence(previous, ordered, removed)
+	}
+
+	roles, err := l.resolveRoles(len(ordered), true)
+	if err != nil {
+		return err
+	}
+
+	construction := &ScConstruction{}
+	stale := append([]ScAddr(nil), removed...)
+	for i, member := range ordered {
+		// Member with several roles is rewritten even if it keeps position
+		if member.position == i+1 && len(member.links) == 1 {
+			continue
+		}
+
+		role := roles[i+1]
+		if !role.IsValid() {
+			return CommonError(ErrInvalidState, fmt.Sprintf("failed to resolve role of position %d", i+1))
+		}
+
+		var arc interface{} = member.arc
+		if !member.arc.IsValid() {
+			alias := fmt.Sprintf("member_%d", i)
+			if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, l.Addr, member.item, alias); err != nil {
+				return err
+			}
+			arc = alias
+		}
+		stale = append(stale, member.links...)
+		if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, role, arc, ""); err != nil {
+			return err
+		}
+	}
+	return l.apply(stale, construction)
+}
+
+// rewriteSequence links members in new order. Only sequence arcs between members which are
+// not neighbours anymore are deleted, arcs incident to removed membership arcs are deleted with them
+func (l *ScList) rewriteSequence(previous, ordered []listMember, removed []ScAddr) error {
+	relation, err := l.sequenceRelation(true)
+	if err != nil {
+		return err
+	}
+
+	isRemoved := make(map[int64]bool, len(removed))
+	for _, arc := range removed {
+		isRemoved[arc.Value] = true
+	}
+
+	// Neighbours of new order which already exist are kept
+	wanted := make(map[[2]int64]bool, len(ordered))
+	for i := 1; i < len(ordered); i++ {
+		if ordered[i-1].arc.IsValid() && ordered[i].arc.IsValid() {
+			wanted[[2]int64{ordered[i-1].arc.Value, ordered[i].arc.Value}] = true
+		}
+	}
+
+	stale := append([]ScAddr(nil), removed...)
+	kept := make(map[[2]int64]bool, len(wanted))
+	for _, member := range previous {
+		if isRemoved[member.arc.Value] {
+			continue
+		}
+		for i, link := range member.links {
+			if isRemoved[member.next[i].Value] {
+				continue
+			}
+			pair := [2]int64{member.arc.Value, member.next[i].Value}
+			if wanted[pair] && !kept[pair] {
+				kept[pair] = true
+				continue
+			}
+			stale = append(stale, link)
+		}
+	}
+
+	construction := &ScConstruction{}
+	refs := make([]interface{}, len(ordered))
+	for i, member := range ordered {
+		refs[i] = member.arc
+		if !member.arc.IsValid() {
+			alias := fmt.Sprintf("member_%d", i)
+			if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, l.Addr, member.item, alias); err != nil {
+				return err
+			}
+			refs[i] = alias
+		}
+	}
+
+	for i := 1; i < len(refs); i++ {
+		if kept[[2]int64{ordered[i-1].arc.Value, ordered[i].arc.Value}] {
+			continue
+		}
+		alias := fmt.Sprintf("seq_%d", i)
+		if err := construction.CreateEdge(ScType{Value: ScTypeDEdgeConst}, refs[i-1], refs[i], alias); err != nil {
+			return err
+		}
+		if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, relation, alias, ""); err != nil {
+			return err
+		}
+	}
+	return l.apply(stale, construction)
+}
+
+// apply creates new position arcs before stale ones are deleted, so members don't lose position
+func (l *ScList) apply(stale []ScAddr, construction *ScConstruction) error {
+	if len(construction.Commands) > 0 {
+		if _, err := l.Client.CreateElements(construction); err != nil {
+			return err
+		}
+	}
+
+	if len(stale) == 0 {
+		return nil
+	}
+	deleted, err := l.Client.DeleteElements(stale)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("failed to delete list position arcs")
+	}
+	return nil
+}
+
+// Watch keeps cached items up to date and calls OnChange when order changes.
+// Membership and position arcs are listened, so reordering calls OnChange too.
+// Watching is stopped when ctx is cancelled or Close is called
+func (l *ScList) Watch(ctx context.Context) error {
+	listeners, err := l.Client.addListeners([]listenerParams{
+		{addr: l.Addr, eventType: ScEventAddOutgoingEdge, callback: l.onEvent},
+		{addr: l.Addr, eventType: ScEventRemoveOutgoingEdge, callback: l.onEvent},
+	})
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.listeners = append(l.listeners, listeners...)
+	l.arcListeners = make(map[int64][]*ScEventListener)
+	l.mu.Unlock()
+
+	if err := l.refresh(true); err != nil {
+		l.Close()
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := l.Close(); err != nil {
+			log.Printf("Failed to close list %v: %v", l.Addr, err)
+		}
+	}()
+	return nil
+}
+
+// onEvent refreshes watched list when its membership or position arcs change
+func (l *ScList) onEvent(elAddr, edge, other ScAddr, eventID int) {
+	if err := l.refresh(false); err != nil {
+		log.Printf("Failed to refresh list %v: %v", l.Addr, err)
+	}
+}
+
+// refresh updates cached items and calls OnChange if they changed. Members are searched again
+// after new membership arcs are listened, so position arcs created meanwhile are not missed.
+// Members without position are reported if strict is set, otherwise they are expected to get
+// position soon and cached items are kept until then
+func (l *ScList) refresh(strict bool) error {
+	l.refreshMu.Lock()
+	defer l.refreshMu.Unlock()
+
+	for {
+		members, unpositioned, err := l.members()
+		if err != nil {
+			return err
+		}
+		tracked, err := l.trackArcs(append(append([]listMember(nil), members...), unpositioned...))
+		if err != nil {
+			return err
+		}
+		if tracked {
+			continue
+		}
+		if len(unpositioned) > 0 {
+			if strict {
+				return l.unpositionedError(members, unpositioned)
+			}
+			return nil
+		}
+
+		items := make([]ScAddr, len(members))
+		for i, member := range members {
+			items[i] = member.item
+		}
+
+		l.mu.Lock()
+		changed := len(items) != len(l.items)
+		for i := 0; !changed && i < len(items); i++ {
+			changed = !items[i].Equal(l.items[i])
+		}
+		l.items = items
+		l.mu.Unlock()
+
+		if changed && l.OnChange != nil {
+			l.OnChange(items)
+		}
+		return nil
+	}
+}
+
+// trackArcs listens for position arcs added to or removed from membership arcs of members and
+// stops listening arcs which aren't members anymore. Returns true if new arcs are listened
+func (l *ScList) trackArcs(members []listMember) (bool, error) {
+	var params []listenerParams
+	var untracked []*ScEventListener
+
+	l.mu.Lock()
+	if l.arcListeners == nil {
+		l.mu.Unlock()
+		return false, nil
+	}
+	current := make(map[int64]bool, len(members))
+	for _, member := range members {
+		current[member.arc.Value] = true
+		if _, tracked := l.arcListeners[member.arc.Value]; tracked {
+			continue
+		}
+		// Role arcs and sequence arcs end in membership arcs
+		params = append(params,
+			listenerParams{addr: member.arc, eventType: ScEventAddIngoingEdge, callback: l.onEvent},
+			listenerParams{addr: member.arc, eventType: ScEventRemoveIngoingEdge, callback: l.onEvent},
+		)
+	}
+	for arc, listeners := range l.arcListeners {
+		if !current[arc] {
+			delete(l.arcListeners, arc)
+			untracked = append(untracked, listeners...)
+		}
+	}
+	l.mu.Unlock()
+
+	if err := l.Client.closeListeners(untracked); err != nil {
+		return false, err
+	}
+	if len(params) == 0 {
+		return false, nil
+	}
+
+	listeners, err := l.Client.addListeners(params)
+	if err != nil {
+		return false, err
+	}
+	l.mu.Lock()
+	if l.arcListeners == nil {
+		l.mu.Unlock()
+		return false, l.Client.closeListeners(listeners)
+	}
+	for i := 0; i < len(listeners); i += 2 {
+		l.arcListeners[params[i].addr.Value] = listeners[i : i+2]
+	}
+	l.mu.Unlock()
+	return true, nil
+}
+
+// Cached returns items known by watching list
+func (l *ScList) Cached() []ScAddr {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]ScAddr(nil), l.items...)
+}
+
+// Close stops watching list
+func (l *ScList) Close() error {
+	l.mu.Lock()
+	listeners := l.listeners
+	for _, arcListeners := range l.arcListeners {
+		listeners = append(listeners, arcListeners...)
+	}
+	l.listeners = nil
+	l.arcListeners = nil
+	l.mu.Unlock()
+
+	return l.Client.closeListeners(listeners)
+}
@@ -0,0 +1,180 @@
+package sc_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	sc "github.com/temapriemnik/go-sc-client"
+	"github.com/temapriemnik/go-sc-client/agenttest"
+)
+
+func assertListItems(t *testing.T, list *sc.ScList, expected ...sc.ScAddr) {
+	t.Helper()
+
+	items, err := list.Items()
+	if err != nil {
+		t.Fatalf("failed to get list items: %v", err)
+	}
+	if len(items) != len(expected) {
+		t.Fatalf("expected items %v, got %v", expected, items)
+	}
+	for i := range items {
+		if !items[i].Equal(expected[i]) {
+			t.Fatalf("expected items %v, got %v", expected, items)
+		}
+	}
+}
+
+func TestScListReportsMembersWithoutPosition(t *testing.T) {
+	kit := agenttest.New(t)
+	addrs := kit.LoadSCs(`
+		rrel_1 <- sc_node_role_relation;;
+		rrel_2 <- sc_node_role_relation;;
+		rrel_5 <- sc_node_role_relation;;
+		roles_list -> rrel_2: ..second;;
+		roles_list -> rrel_1: ..first;;
+		roles_list -> rrel_5: ..far;;
+		roles_list -> ..unordered;;
+	`)
+
+	list, err := sc.NewScList(kit.Client, addrs["roles_list"], sc.ScListOrderByRoles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = list.Items()
+	if err == nil || !strings.HasPrefix(err.Error(), sc.ErrInvalidState.Error()) {
+		t.Fatalf("expected error about members without position, got %v", err)
+	}
+
+	// Members without position get positions after ordered members
+	if err := list.Append(addrs["..unordered"]); err != nil {
+		t.Fatalf("failed to append item: %v", err)
+	}
+	items, err := list.Items()
+	if err != nil {
+		t.Fatalf("failed to get list items: %v", err)
+	}
+	if len(items) != 5 || !items[0].Equal(addrs["..first"]) || !items[1].Equal(addrs["..second"]) || !items[4].Equal(addrs["..unordered"]) {
+		t.Errorf("unexpected items %v", items)
+	}
+
+	template := &sc.ScTemplate{}
+	template.TripleWithRelation(
+		addrs["roles_list"],
+		sc.ScType{Value: sc.ScTypeArcPosVarPerm},
+		addrs["..far"],
+		sc.ScType{Value: sc.ScTypeArcPosVarPerm},
+		addrs["rrel_5"],
+	)
+	kit.AssertNoMatch(template, nil)
+}
+
+func TestScListSequenceIsAnchoredAndRewrittenMinimally(t *testing.T) {
+	kit := agenttest.New(t)
+	addrs := kit.LoadSCs(`
+		@a = (sequence_list -> ..a);;
+		@b = (sequence_list -> ..b);;
+		@c = (sequence_list -> ..c);;
+		@d = (sequence_list -> ..d);;
+		@ab = (@a => @b);;
+		nrel_basic_sequence -> @ab;;
+		@bc = (@b => @c);;
+		nrel_basic_sequence -> @bc;;
+		@cd = (@c => @d);;
+		nrel_basic_sequence -> @cd;;
+
+		@x = (other_list -> ..x);;
+		@y = (other_list -> ..y);;
+		@xy = (@x => @y);;
+		nrel_basic_sequence -> @xy;;
+	`)
+
+	list, err := sc.NewScList(kit.Client, addrs["sequence_list"], sc.ScListOrderBySequence)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertListItems(t, list, addrs["..a"], addrs["..b"], addrs["..c"], addrs["..d"])
+
+	if err := list.Move(0, 3); err != nil {
+		t.Fatalf("failed to move item: %v", err)
+	}
+	assertListItems(t, list, addrs["..b"], addrs["..c"], addrs["..d"], addrs["..a"])
+
+	// Links of neighbours kept by move are not recreated
+	types, err := kit.Client.CheckElements([]sc.ScAddr{addrs["@ab"], addrs["@bc"], addrs["@cd"], addrs["@xy"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types[0].IsValid() || !types[1].IsValid() || !types[2].IsValid() || !types[3].IsValid() {
+		t.Errorf("unexpected sequence arcs after move: %v", types)
+	}
+}
+
+func TestScListWatchReportsReorder(t *testing.T) {
+	for name, ordering := range map[string]sc.ScListOrdering{"roles": sc.ScListOrderByRoles, "sequence": sc.ScListOrderBySequence} {
+		t.Run(name, func(t *testing.T) {
+			kit := agenttest.New(t)
+			addrs := kit.LoadSCs(`
+				rrel_1 <- sc_node_role_relation;;
+				rrel_2 <- sc_node_role_relation;;
+				rrel_3 <- sc_node_role_relation;;
+				watched_list <- sc_node_tuple;;
+				..a <- concept_item;;
+				..b <- concept_item;;
+				..c <- concept_item;;
+			`)
+			list, err := sc.NewScList(kit.Client, addrs["watched_list"], ordering)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var mu sync.Mutex
+			var changed []sc.ScAddr
+			list.OnChange = func(items []sc.ScAddr) {
+				mu.Lock()
+				changed = items
+				mu.Unlock()
+			}
+			if err := list.Watch(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			defer list.Close()
+
+			reported := func(expected ...sc.ScAddr) func() bool {
+				return func() bool {
+					mu.Lock()
+					defer mu.Unlock()
+					if len(changed) != len(expected) {
+						return false
+					}
+					for i := range changed {
+						if !changed[i].Equal(expected[i]) {
+							return false
+						}
+					}
+					return true
+				}
+			}
+
+			for _, item := range []string{"..a", "..b", "..c"} {
+				if err := list.Append(addrs[item]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			waitFor(t, reported(addrs["..a"], addrs["..b"], addrs["..c"]))
+
+			// Move keeps membership arcs and rewrites only position arcs
+			if err := list.Move(0, 2); err != nil {
+				t.Fatal(err)
+			}
+			waitFor(t, reported(addrs["..b"], addrs["..c"], addrs["..a"]))
+			if err := list.Insert(0, addrs["..a"]); err != nil {
+				t.Fatal(err)
+			}
+			waitFor(t, reported(addrs["..a"], addrs["..b"], addrs["..c"], addrs["..a"]))
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package sc
+
+import (
+	"context"
+	"fmt"
+)
+
+// NeighbourhoodOptions configures loading of neighbourhood. Zero value has no filters and limits
+type NeighbourhoodOptions struct {
+	// EdgeType is a mask edges should have all bits of
+	EdgeType *ScType
+	// ElementType is a mask reached nodes and links should have all bits of
+	ElementType *ScType
+	// MaxElements limits number of nodes and links when positive
+	MaxElements int
+	// MaxEdges limits number of edges when positive
+	MaxEdges int
+	// LoadContents enables loading of link contents
+	LoadContents bool
+}
+
+// neighbourhoodEdgeTypes are types of edges searched separately around every element
+var neighbourhoodEdgeTypes = []int{
+	ScTypeEdgeAccess | ScTypeVar,
+	ScTypeDEdgeCommon | ScTypeVar,
+	ScTypeUEdgeCommon | ScTypeVar,
+}
+
+// neighbourRow represents edge found around element
+type neighbourRow struct {
+	edge, src, trg ScAddr
+}
+
+// neighbourhood loads elements around root level by level
+type neighbourhood struct {
+	client *ScClient
+	graph  *Graph
+	opts   NeighbourhoodOptions
+}
+
+// Neighbourhood returns graph of elements reachable from addr within depth edges in both directions.
+// Edges going to found edges (relation membership and roles) are loaded on the same level, so level
+// takes several rounds of requests. Searches of every round are sent at once and types of elements
+// found by round are checked with one request, ends of edges found as ends are loaded with one more
+// round. Ends of edge passed as addr are not filtered by element type. Opts may be nil
+func (c *ScClient) Neighbourhood(ctx context.Context, addr ScAddr, depth int, opts *NeighbourhoodOptions) (*Graph, error) {
+	n := &neighbourhood{client: c, graph: NewGraph(addr)}
+	if opts != nil {
+		n.opts = *opts
+	}
+
+	types, err := c.CheckElements([]ScAddr{addr})
+	if err != nil {
+		return nil, err
+	}
+	if len(types) == 0 || !types[0].IsValid() {
+		return nil, CommonError(ErrElementNotFound, fmt.Sprintf("element %v", addr))
+	}
+
+	frontier := []ScAddr{addr}
+	if types[0].IsEdge() {
+		rows, err := n.edgeEnds([]ScAddr{addr})
+		if err != nil {
+			return nil, err
+		}
+		edges, elements, err := n.addRows(rows, true)
+		if err != nil {
+			return nil, err
+		}
+		if len(edges) == 0 {
+			return nil, CommonError(ErrElementNotFound, fmt.Sprintf("ends of edge %v", addr))
+		}
+		frontier = append(frontier, elements...)
+	} else if _, err := n.graph.AddElement(addr, types[0]); err != nil {
+		return nil, err
+	}
+
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if frontier, err = n.expand(ctx, frontier); err != nil {
+			return nil, err
+		}
+	}
+
+	if n.opts.LoadContents {
+		if err := n.loadContents(); err != nil {
+			return nil, err
+		}
+	}
+	return n.graph, nil
+}
+
+// expand loads edges of frontier elements and edges going to them. Returns reached elements
+func (n *neighbourhood) expand(ctx context.Context, frontier []ScAddr) ([]ScAddr, error) {
+	rows, err := n.search(frontier, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var next []ScAddr
+	for len(rows) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		edges, elements, err := n.addRows(rows, false)
+		if err != nil {
+			return nil, err
+		}
+		next = append(next, elements...)
+
+		// Arcs over found edges belong to the same level
+		if rows, err = n.search(edges, false); err != nil {
+			return nil, err
+		}
+	}
+	return next, nil
+}
+
+// search finds edges going to elements and, if outgoing is set, from them
+func (n *neighbourhood) search(addrs []ScAddr, outgoing bool) ([]neighbourRow, error) {
+	type request struct {
+		result   <-chan *ScTemplateResultSet
+		incoming bool
+	}
+
+	var requests []request
+	for _, addr := range addrs {
+		for _, edgeType := range neighbourhoodEdgeTypes {
+			template := &ScTemplate{}
+			template.Triple([]interface{}{ScType{}, "_src"}, []interface{}{ScType{Value: edgeType}, "_edge"}, addr)
+			result, err := n.client.sendTemplateSearch(template, nil)
+			if err != nil {
+				return nil, err
+			}
+			requests = append(requests, request{result: result, incoming: true})
+
+			if !outgoing {
+				continue
+			}
+			template = &ScTemplate{}
+			template.Triple(addr, []interface{}{ScType{Value: edgeType}, "_edge"}, []interface{}{ScType{}, "_trg"})
+			if result, err = n.client.sendTemplateSearch(template, nil); err != nil {
+				return nil, err
+			}
+			requests = append(requests, request{result: result})
+		}
+	}
+
+	seen := make(map[int64]bool)
+	var rows []neighbourRow
+	for _, r := range requests {
+		set, err := waitTemplateSearch(r.result)
+		if err != nil {
+			return nil, err
+		}
+		set.ForEach(func(row ScTemplateResult) bool {
+			edge := row.Get("_edge")
+			if seen[edge.Value] || n.graph.Contains(edge) {
+				return true
+			}
+			seen[edge.Value] = true
+			rows = append(rows, neighbourRow{edge: edge, src: row.Get(0), trg: row.Get(2)})
+			return true
+		})
+	}
+	return rows, nil
+}
+
+// edgeEnds finds sources and targets of edges
+func (n *neighbourhood) edgeEnds(edges []ScAddr) ([]neighbourRow, error) {
+	requests := make([]<-chan *ScTemplateResultSet, len(edges))
+	for i, edge := range edges {
+		template := &ScTemplate{}
+		template.Triple([]interface{}{ScType{}, "_src"}, edge, []interface{}{ScType{}, "_trg"})
+		request, err := n.client.sendTemplateSearch(template, nil)
+		if err != nil {
+			return nil, err
+		}
+		requests[i] = request
+	}
+
+	var rows []neighbourRow
+	for i, request := range requests {
+		set, err := waitTemplateSearch(request)
+		if err != nil {
+			return nil, err
+		}
+		if set.Len() > 0 {
+			row := set.Row(0)
+			rows = append(rows, neighbourRow{edge: edges[i], src: row.Get("_src"), trg: row.Get("_trg")})
+		}
+	}
+	return rows, nil
+}
+
+// addRows checks types of found elements and adds ones passing filters and limits.
+// Ends which are edges are loaded with their own ends first, they are filtered like ends of
+// rows. Ends of edges requested explicitly are not filtered by element type.
+// Returns added edges and other elements
+func (n *neighbourhood) addRows(rows []neighbourRow, explicit bool) ([]ScAddr, []ScAddr, error) {
+	var unknown []ScAddr
+	seen := make(map[int64]bool)
+	rowEdges := make(map[int64]bool, len(rows))
+	for _, row := range rows {
+		rowEdges[row.edge.Value] = true
+		for _, addr := range []ScAddr{row.edge, row.src, row.trg} {
+			if !seen[addr.Value] && !n.graph.Contains(addr) {
+				seen[addr.Value] = true
+				unknown = append(unknown, addr)
+			}
+		}
+	}
+
+	types, err := n.client.CheckElements(unknown)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(types) != len(unknown) {
+		return nil, nil, CommonError(ErrInvalidState, fmt.Sprintf("got %d types of %d neighbourhood elements", len(types), len(unknown)))
+	}
+	typeOf := make(map[int64]ScType, len(unknown))
+	var endEdges []ScAddr
+	for i, addr := range unknown {
+		typeOf[addr.Value] = types[i]
+		if types[i].IsEdge() && !rowEdges[addr.Value] {
+			endEdges = append(endEdges, addr)
+		}
+	}
+
+	var edges, elements []ScAddr
+	if len(endEdges) > 0 {
+		endRows, err := n.edgeEnds(endEdges)
+		if err != nil {
+			return nil, nil, err
+		}
+		if edges, elements, err = n.addRows(endRows, explicit); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, row := range rows {
+		if n.graph.Contains(row.edge) {
+			continue
+		}
+		edgeType := typeOf[row.edge.Value]
+		if !edgeType.IsEdge() || !matchesMask(edgeType, n.opts.EdgeType) {
+			continue
+		}
+
+		var ends []ScAddr
+		allowed := true
+		for _, end := range []ScAddr{row.src, row.trg} {
+			if n.graph.Contains(end) {
+				continue
+			}
+			t := typeOf[end.Value]
+			if !t.IsValid() || t.IsEdge() || (!explicit && !matchesMask(t, n.opts.ElementType)) {
+				allowed = false
+				break
+			}
+			if len(ends) == 0 || !ends[0].Equal(end) {
+				ends = append(ends, end)
+			}
+		}
+		if !allowed {
+			continue
+		}
+
+		if (n.opts.MaxElements > 0 && n.graph.NodesCount()+len(ends) > n.opts.MaxElements) ||
+			(n.opts.MaxEdges > 0 && n.graph.EdgesCount() >= n.opts.MaxEdges) {
+			n.graph.Truncated = true
+			continue
+		}
+
+		for _, end := range ends {
+			if _, err := n.graph.AddElement(end, typeOf[end.Value]); err != nil {
+				return nil, nil, err
+			}
+			elements = append(elements, end)
+		}
+		if _, err := n.graph.AddEdge(row.edge, edgeType, row.src, row.trg); err != nil {
+			return nil, nil, err
+		}
+		edges = append(edges, row.edge)
+	}
+	return edges, elements, nil
+}
+
+// matchesMask checks if type has all bits of mask. Nil mask matches any type
+func matchesMask(t ScType, mask *ScType) bool {
+	return mask == nil || (t.Value&mask.Value) == mask.Value
+}
+
+// loadContents loads contents of all links in graph with one request
+func (n *neighbourhood) loadContents() error {
+	var links []ScAddr
+	for _, el := range n.graph.Nodes() {
+		if el.Type.IsLink() {
+			links = append(links, el.Addr)
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+
+	contents, err := n.client.getLinkContents(links)
+	if err != nil {
+		return err
+	}
+	for i, link := range links {
+		if i < len(contents) {
+			n.graph.Element(link).Content = contents[i]
+		}
+	}
+	return nil
+}
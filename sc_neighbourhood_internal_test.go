@@ -0,0 +1,20 @@
+package sc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNeighbourhoodFailsOnMissingTypes(t *testing.T) {
+	server := newTestServer(t, func(request Request) Response {
+		return Response{Status: true, Payload: []interface{}{ScTypeArcPosConstPerm}}
+	})
+	client := NewScClient(testServerURL(server))
+	defer client.Close()
+
+	n := &neighbourhood{client: client, graph: NewGraph(ScAddr{Value: 1})}
+	rows := []neighbourRow{{edge: ScAddr{Value: 2}, src: ScAddr{Value: 1}, trg: ScAddr{Value: 3}}}
+	if _, _, err := n.addRows(rows, false); err == nil || !strings.HasPrefix(err.Error(), ErrInvalidState.Error()) {
+		t.Errorf("expected invalid state error, got %v", err)
+	}
+}
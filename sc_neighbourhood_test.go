@@ -0,0 +1,173 @@
+package sc_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	sc "github.com/temapriemnik/go-sc-client"
+	"github.com/temapriemnik/go-sc-client/agenttest"
+)
+
+// loadNeighbourhood loads a => rel: b -> c -> d with link of a
+func loadNeighbourhood(tb testing.TB) (*agenttest.Kit, map[string]sc.ScAddr) {
+	tb.Helper()
+
+	kit := agenttest.New(tb)
+	return kit, kit.LoadSCs(`
+		..a <- sc_node_class;;
+		..b <- sc_node_class;;
+		..c <- sc_node;;
+		..d <- sc_node;;
+		..rel <- sc_node_norole_relation;;
+		@ab = (..a => ..b);;
+		@x = (..rel -> @ab);;
+		@bc = (..b -> ..c);;
+		@cd = (..c -> ..d);;
+		@link = (..a -> ..text);;
+		..text = [text];;
+	`)
+}
+
+// assertGraph checks that graph contains exactly expected elements
+func assertGraph(tb testing.TB, g *sc.Graph, addrs map[string]sc.ScAddr, expected ...string) {
+	tb.Helper()
+
+	for _, name := range expected {
+		if !g.Contains(addrs[name]) {
+			tb.Errorf("%s is missing in graph", name)
+		}
+	}
+	if g.Len() != len(expected) {
+		var found []string
+		for name, addr := range addrs {
+			if g.Contains(addr) {
+				found = append(found, name)
+			}
+		}
+		tb.Errorf("expected %d elements, got %d: %v", len(expected), g.Len(), found)
+	}
+}
+
+func TestNeighbourhoodDepth(t *testing.T) {
+	kit, addrs := loadNeighbourhood(t)
+
+	g, err := kit.Client.Neighbourhood(context.Background(), addrs["..a"], 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGraph(t, g, addrs, "..a")
+
+	// Arc over found edge is loaded on the same level
+	g, err = kit.Client.Neighbourhood(context.Background(), addrs["..a"], 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGraph(t, g, addrs, "..a", "..b", "@ab", "..rel", "@x", "..text", "@link")
+	if !g.Root.Equal(addrs["..a"]) || g.Truncated {
+		t.Errorf("unexpected root %v or truncation", g.Root)
+	}
+
+	g, err = kit.Client.Neighbourhood(context.Background(), addrs["..a"], 2, &sc.NeighbourhoodOptions{LoadContents: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGraph(t, g, addrs, "..a", "..b", "@ab", "..rel", "@x", "..text", "@link", "..c", "@bc")
+	if content := g.Element(addrs["..text"]).Content; content == nil || content.Data != "text" {
+		t.Errorf("unexpected link content %+v", content)
+	}
+
+	if _, err := kit.Client.Neighbourhood(context.Background(), sc.ScAddr{Value: 1000}, 1, nil); err == nil || !strings.HasPrefix(err.Error(), sc.ErrElementNotFound.Error()) {
+		t.Errorf("expected element not found error, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := kit.Client.Neighbourhood(ctx, addrs["..a"], 1, nil); err == nil {
+		t.Error("neighbourhood is loaded with cancelled context")
+	}
+}
+
+func TestNeighbourhoodFilters(t *testing.T) {
+	kit, addrs := loadNeighbourhood(t)
+
+	// Edges ending in filtered elements are skipped
+	g, err := kit.Client.Neighbourhood(context.Background(), addrs["..a"], 2, &sc.NeighbourhoodOptions{
+		ElementType: &sc.ScType{Value: sc.ScTypeNodeConstClass},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGraph(t, g, addrs, "..a", "..b", "@ab")
+
+	g, err = kit.Client.Neighbourhood(context.Background(), addrs["..a"], 2, &sc.NeighbourhoodOptions{
+		EdgeType: &sc.ScType{Value: sc.ScTypeArcPosConstPerm},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGraph(t, g, addrs, "..a", "..text", "@link")
+
+	// Ends of edge reached by arc over it are filtered by element type
+	g, err = kit.Client.Neighbourhood(context.Background(), addrs["..rel"], 1, &sc.NeighbourhoodOptions{
+		ElementType: &sc.ScType{Value: sc.ScTypeNodeConstNoRole},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGraph(t, g, addrs, "..rel")
+	g, err = kit.Client.Neighbourhood(context.Background(), addrs["..rel"], 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGraph(t, g, addrs, "..rel", "@x", "@ab", "..a", "..b")
+}
+
+func TestNeighbourhoodLimits(t *testing.T) {
+	kit, addrs := loadNeighbourhood(t)
+
+	g, err := kit.Client.Neighbourhood(context.Background(), addrs["..a"], 2, &sc.NeighbourhoodOptions{MaxElements: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Truncated || g.NodesCount() != 2 {
+		t.Errorf("elements are not limited: %d elements, truncated %v", g.NodesCount(), g.Truncated)
+	}
+
+	g, err = kit.Client.Neighbourhood(context.Background(), addrs["..a"], 2, &sc.NeighbourhoodOptions{MaxEdges: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Truncated || g.EdgesCount() != 1 {
+		t.Errorf("edges are not limited: %d edges, truncated %v", g.EdgesCount(), g.Truncated)
+	}
+
+	g, err = kit.Client.Neighbourhood(context.Background(), addrs["..a"], 2, &sc.NeighbourhoodOptions{MaxElements: 100, MaxEdges: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Truncated {
+		t.Error("graph within limits is truncated")
+	}
+}
+
+func TestNeighbourhoodOfEdge(t *testing.T) {
+	kit, addrs := loadNeighbourhood(t)
+
+	// Ends of root edge are loaded regardless of element type
+	g, err := kit.Client.Neighbourhood(context.Background(), addrs["@ab"], 0, &sc.NeighbourhoodOptions{
+		ElementType: &sc.ScType{Value: sc.ScTypeNodeConstNoRole},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGraph(t, g, addrs, "@ab", "..a", "..b")
+	if el := g.Element(addrs["@ab"]); el == nil || !el.Source.Equal(addrs["..a"]) || !el.Target.Equal(addrs["..b"]) {
+		t.Errorf("unexpected root edge %+v", el)
+	}
+
+	g, err = kit.Client.Neighbourhood(context.Background(), addrs["@ab"], 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGraph(t, g, addrs, "@ab", "..a", "..b", "..rel", "@x", "..text", "@link", "..c", "@bc")
+}
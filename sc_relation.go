@@ -0,0 +1,188 @@
+package sc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// resolveElement returns address of element passed as ScAddr or system identifier
+func (c *ScClient) resolveElement(addrOrIdtf interface{}) (ScAddr, error) {
+	switch v := addrOrIdtf.(type) {
+	case ScAddr:
+		if !v.IsValid() {
+			return ScAddr{}, InvalidValueError("invalid addr of element")
+		}
+		return v, nil
+	case string:
+		return c.Keynode(v)
+	default:
+		return ScAddr{}, CommonError(ErrInvalidParameters, "element should be ScAddr or system identifier")
+	}
+}
+
+// relationTemplate returns template of src => rel: trg where src or trg may be unknown
+func relationTemplate(src, rel, trg interface{}) *ScTemplate {
+	template := &ScTemplate{}
+	template.TripleWithRelation(
+		src,
+		[]interface{}{ScType{Value: ScTypeDEdgeVar}, "_edge"},
+		trg,
+		[]interface{}{ScType{Value: ScTypeArcPosVarPerm}, "_rel_arc"},
+		rel,
+	)
+	return template
+}
+
+// GetRelationTargets returns targets of src => rel: _trg. Relation is ScAddr or system identifier
+func (c *ScClient) GetRelationTargets(src ScAddr, rel interface{}) ([]ScAddr, error) {
+	relAddr, err := c.resolveElement(rel)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := c.TemplateSearchSet(relationTemplate(src, relAddr, []interface{}{ScType{}, "_trg"}))
+	if err != nil {
+		return nil, err
+	}
+	return set.Distinct("_trg"), nil
+}
+
+// GetRelationSources returns sources of _src => rel: trg. Relation is ScAddr or system identifier
+func (c *ScClient) GetRelationSources(trg ScAddr, rel interface{}) ([]ScAddr, error) {
+	relAddr, err := c.resolveElement(rel)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := c.TemplateSearchSet(relationTemplate([]interface{}{ScType{}, "_src"}, relAddr, trg))
+	if err != nil {
+		return nil, err
+	}
+	return set.Distinct("_src"), nil
+}
+
+// GetRelationTarget returns the only target of src => rel: _trg
+func (c *ScClient) GetRelationTarget(src ScAddr, rel interface{}) (ScAddr, error) {
+	targets, err := c.GetRelationTargets(src, rel)
+	if err != nil {
+		return ScAddr{}, err
+	}
+	if len(targets) == 0 {
+		return ScAddr{}, CommonError(ErrElementNotFound, fmt.Sprintf("relation target of %v", src))
+	}
+	return targets[0], nil
+}
+
+// AddRelation creates src => rel: trg and returns created common arc
+func (c *ScClient) AddRelation(src ScAddr, rel interface{}, trg ScAddr) (ScAddr, error) {
+	relAddr, err := c.resolveElement(rel)
+	if err != nil {
+		return ScAddr{}, err
+	}
+
+	construction := &ScConstruction{}
+	if err := construction.CreateEdge(ScType{Value: ScTypeDEdgeConst}, src, trg, "edge"); err != nil {
+		return ScAddr{}, err
+	}
+	if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, relAddr, "edge", ""); err != nil {
+		return ScAddr{}, err
+	}
+
+	addrs, err := c.CreateElements(construction)
+	if err != nil {
+		return ScAddr{}, err
+	}
+	return addrs[0], nil
+}
+
+// SetRelation replaces all targets of src => rel: _trg by trg. New arc is created before
+// old ones are deleted, so src always has target of relation
+func (c *ScClient) SetRelation(src ScAddr, rel interface{}, trg ScAddr) (ScAddr, error) {
+	relAddr, err := c.resolveElement(rel)
+	if err != nil {
+		return ScAddr{}, err
+	}
+
+	old, err := c.relationEdges(relationTemplate(src, relAddr, []interface{}{ScType{}, "_trg"}))
+	if err != nil {
+		return ScAddr{}, err
+	}
+	edge, err := c.AddRelation(src, relAddr, trg)
+	if err != nil {
+		return ScAddr{}, err
+	}
+	if err := c.deleteRelationEdges(old); err != nil {
+		return ScAddr{}, err
+	}
+	return edge, nil
+}
+
+// RemoveRelation deletes common arcs of src => rel: trg. Returns number of deleted arcs
+func (c *ScClient) RemoveRelation(src ScAddr, rel interface{}, trg ScAddr) (int, error) {
+	relAddr, err := c.resolveElement(rel)
+	if err != nil {
+		return 0, err
+	}
+	return c.removeRelationEdges(relationTemplate(src, relAddr, trg))
+}
+
+func (c *ScClient) removeRelationEdges(template *ScTemplate) (int, error) {
+	edges, err := c.relationEdges(template)
+	if err != nil {
+		return 0, err
+	}
+	if err := c.deleteRelationEdges(edges); err != nil {
+		return 0, err
+	}
+	return len(edges), nil
+}
+
+// relationEdges returns common arcs found by relation template
+func (c *ScClient) relationEdges(template *ScTemplate) ([]ScAddr, error) {
+	set, err := c.TemplateSearchSet(template)
+	if err != nil {
+		return nil, err
+	}
+	return set.Distinct("_edge"), nil
+}
+
+func (c *ScClient) deleteRelationEdges(edges []ScAddr) error {
+	if len(edges) == 0 {
+		return nil
+	}
+
+	deleted, err := c.DeleteElements(edges)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("failed to delete relation arcs")
+	}
+	return nil
+}
+
+// TupleGet returns element of tuple having role rrel. Role is ScAddr or system identifier
+func (c *ScClient) TupleGet(tuple ScAddr, rrel interface{}) (ScAddr, error) {
+	roleAddr, err := c.resolveElement(rrel)
+	if err != nil {
+		return ScAddr{}, err
+	}
+
+	template := &ScTemplate{}
+	template.TripleWithRelation(
+		tuple,
+		[]interface{}{ScType{Value: ScTypeEdgeAccess | ScTypeVar}, "_arc"},
+		[]interface{}{ScType{}, "_el"},
+		[]interface{}{ScType{Value: ScTypeArcPosVarPerm}, "_role_arc"},
+		roleAddr,
+	)
+
+	set, err := c.TemplateSearchSet(template)
+	if err != nil {
+		return ScAddr{}, err
+	}
+	if set.Len() == 0 {
+		return ScAddr{}, CommonError(ErrElementNotFound, fmt.Sprintf("element of tuple %v with role %v", tuple, roleAddr))
+	}
+	return set.Row(0).Get("_el"), nil
+}
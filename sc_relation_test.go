@@ -0,0 +1,153 @@
+package sc_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	sc "github.com/temapriemnik/go-sc-client"
+	"github.com/temapriemnik/go-sc-client/agenttest"
+)
+
+func TestRelationTargetsAndSources(t *testing.T) {
+	kit := agenttest.New(t)
+	addrs := kit.LoadSCs(`
+		..minsk => nrel_capital_of: ..belarus;;
+		..brest => nrel_city_of: ..belarus;;
+		..minsk => nrel_city_of: ..belarus;;
+		..minsk => nrel_city_of: ..europe;;
+	`)
+
+	targets, err := kit.Client.GetRelationTargets(addrs["..minsk"], "nrel_city_of")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 || !targets[0].Equal(addrs["..belarus"]) || !targets[1].Equal(addrs["..europe"]) {
+		t.Errorf("unexpected targets %v", targets)
+	}
+	sources, err := kit.Client.GetRelationSources(addrs["..belarus"], addrs["nrel_city_of"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 2 || !sources[0].Equal(addrs["..brest"]) || !sources[1].Equal(addrs["..minsk"]) {
+		t.Errorf("unexpected sources %v", sources)
+	}
+
+	if target, err := kit.Client.GetRelationTarget(addrs["..minsk"], "nrel_capital_of"); err != nil || !target.Equal(addrs["..belarus"]) {
+		t.Errorf("unexpected target %v, %v", target, err)
+	}
+	if _, err := kit.Client.GetRelationTarget(addrs["..brest"], "nrel_capital_of"); err == nil || !strings.HasPrefix(err.Error(), sc.ErrElementNotFound.Error()) {
+		t.Errorf("expected element not found error, got %v", err)
+	}
+	if _, err := kit.Client.GetRelationTargets(addrs["..minsk"], 1); err == nil || !strings.HasPrefix(err.Error(), sc.ErrInvalidParameters.Error()) {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+	if _, err := kit.Client.GetRelationTargets(addrs["..minsk"], "nrel_missing"); err == nil {
+		t.Error("missing relation is resolved")
+	}
+}
+
+func TestAddAndRemoveRelation(t *testing.T) {
+	kit := agenttest.New(t)
+	addrs := kit.LoadSCs(`
+		..a <- concept_item;;
+		..b <- concept_item;;
+		nrel_linked <- sc_node_norole_relation;;
+	`)
+
+	edge, err := kit.Client.AddRelation(addrs["..a"], "nrel_linked", addrs["..b"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kit.Client.AddRelation(addrs["..a"], addrs["nrel_linked"], addrs["..b"]); err != nil {
+		t.Fatal(err)
+	}
+	template := &sc.ScTemplate{}
+	template.TripleWithRelation(addrs["..a"], sc.ScType{Value: sc.ScTypeDEdgeVar}, addrs["..b"], sc.ScType{Value: sc.ScTypeArcPosVarPerm}, addrs["nrel_linked"])
+	if results := kit.AssertTemplateExists(template, nil); len(results) != 2 || !results[0].Get(1).Equal(edge) {
+		t.Errorf("unexpected relation arcs %v", results)
+	}
+
+	removed, err := kit.Client.RemoveRelation(addrs["..a"], "nrel_linked", addrs["..b"])
+	if err != nil || removed != 2 {
+		t.Errorf("unexpected removal %d, %v", removed, err)
+	}
+	kit.AssertNoMatch(template, nil)
+	if removed, err := kit.Client.RemoveRelation(addrs["..a"], "nrel_linked", addrs["..b"]); err != nil || removed != 0 {
+		t.Errorf("unexpected second removal %d, %v", removed, err)
+	}
+}
+
+func TestSetRelationCreatesTargetBeforeDeletingOld(t *testing.T) {
+	kit := agenttest.New(t)
+	addrs := kit.LoadSCs(`
+		..item => nrel_state: ..old;;
+		..item => nrel_state: ..older;;
+		..item => nrel_other: ..kept;;
+		..new <- concept_state;;
+	`)
+
+	var mu sync.Mutex
+	var events []sc.ScEventType
+	for _, eventType := range []sc.ScEventType{sc.ScEventAddOutgoingEdge, sc.ScEventRemoveOutgoingEdge} {
+		eventType := eventType
+		listener, err := kit.Client.AddListener(addrs["..item"], eventType, func(elAddr, edge, other sc.ScAddr, eventID int) {
+			mu.Lock()
+			events = append(events, eventType)
+			mu.Unlock()
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer listener.Close()
+	}
+
+	edge, err := kit.Client.SetRelation(addrs["..item"], "nrel_state", addrs["..new"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if targets, err := kit.Client.GetRelationTargets(addrs["..item"], "nrel_state"); err != nil || len(targets) != 1 || !targets[0].Equal(addrs["..new"]) {
+		t.Errorf("unexpected targets %v, %v", targets, err)
+	}
+	if targets, err := kit.Client.GetRelationTargets(addrs["..item"], "nrel_other"); err != nil || len(targets) != 1 {
+		t.Errorf("targets of other relation are changed: %v, %v", targets, err)
+	}
+	if types, err := kit.Client.CheckElements([]sc.ScAddr{edge}); err != nil || !types[0].IsValid() {
+		t.Errorf("returned arc doesn't exist: %v, %v", types, err)
+	}
+
+	// Item always has target: new arc is created before old ones are deleted
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(events) == 3
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if events[0] != sc.ScEventAddOutgoingEdge || events[1] != sc.ScEventRemoveOutgoingEdge || events[2] != sc.ScEventRemoveOutgoingEdge {
+		t.Errorf("unexpected order of events %v", events)
+	}
+}
+
+func TestTupleGet(t *testing.T) {
+	kit := agenttest.New(t)
+	addrs := kit.LoadSCs(`
+		rrel_key <- sc_node_role_relation;;
+		rrel_value <- sc_node_role_relation;;
+		..pair -> rrel_key: ..key;;
+		..pair -> rrel_value: ..value;;
+	`)
+
+	if el, err := kit.Client.TupleGet(addrs["..pair"], "rrel_key"); err != nil || !el.Equal(addrs["..key"]) {
+		t.Errorf("unexpected key %v, %v", el, err)
+	}
+	if el, err := kit.Client.TupleGet(addrs["..pair"], addrs["rrel_value"]); err != nil || !el.Equal(addrs["..value"]) {
+		t.Errorf("unexpected value %v, %v", el, err)
+	}
+	if _, err := kit.Client.TupleGet(addrs["..key"], "rrel_key"); err == nil || !strings.HasPrefix(err.Error(), sc.ErrElementNotFound.Error()) {
+		t.Errorf("expected element not found error, got %v", err)
+	}
+	if _, err := kit.Client.TupleGet(addrs["..pair"], sc.ScAddr{}); err == nil {
+		t.Error("invalid role is accepted")
+	}
+}
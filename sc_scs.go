@@ -0,0 +1,192 @@
+package sc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// scsConnector returns SCs connector for edge type
+func scsConnector(t ScType) string {
+	var connector string
+	switch {
+	case (t.Value & ScTypeUEdgeCommon) != 0:
+		connector = "<=>"
+	case (t.Value & ScTypeDEdgeCommon) != 0:
+		connector = "=>"
+	case (t.Value & ScTypeEdgeAccess) != 0:
+		line := "-"
+		if (t.Value & ScTypeEdgeTemp) != 0 {
+			line = "~"
+		}
+		switch {
+		case (t.Value & ScTypeEdgePos) != 0:
+			connector = line + ">"
+		case (t.Value & ScTypeEdgeNeg) != 0:
+			connector = line + "|>"
+		case (t.Value & ScTypeEdgeFuz) != 0:
+			connector = line + "/>"
+		default:
+			connector = "..>"
+		}
+	default:
+		connector = "?>"
+	}
+
+	if t.IsVar() {
+		return "_" + connector
+	}
+	return connector
+}
+
+// scsWriter collects SCs sentences and names of elements
+type scsWriter struct {
+	lines     []string
+	types     []string
+	edges     map[string]bool
+	declared  map[string]bool
+	anonymous int
+}
+
+func newScsWriter() *scsWriter {
+	return &scsWriter{
+		edges:    make(map[string]bool),
+		declared: make(map[string]bool),
+	}
+}
+
+// name returns SCs identifier of alias, edges are referenced with "@"
+func (w *scsWriter) name(alias string) string {
+	if w.edges[alias] {
+		return "@" + alias
+	}
+	return alias
+}
+
+func (w *scsWriter) nextAnonymous(isVar bool) string {
+	w.anonymous++
+	if isVar {
+		return fmt.Sprintf("_el%d", w.anonymous)
+	}
+	return fmt.Sprintf("..el%d", w.anonymous)
+}
+
+// declareType adds type declaration of node or link
+func (w *scsWriter) declareType(name string, t ScType) {
+	if w.declared[name] || !t.IsValid() || t.IsEdge() {
+		return
+	}
+	w.declared[name] = true
+
+	keynode := "sc_link"
+	if t.IsNode() {
+		keynode = nodeTypeKeynode(t)
+	}
+	w.types = append(w.types, fmt.Sprintf("%s <- %s;;", name, keynode))
+}
+
+func (w *scsWriter) triple(edgeAlias, src, connector, trg string) {
+	if edgeAlias != "" {
+		w.edges[edgeAlias] = true
+		w.lines = append(w.lines, fmt.Sprintf("@%s = (%s %s %s);;", edgeAlias, src, connector, trg))
+		return
+	}
+	w.lines = append(w.lines, fmt.Sprintf("%s %s %s;;", src, connector, trg))
+}
+
+func (w *scsWriter) String() string {
+	return strings.Join(append(w.lines, w.types...), "\n")
+}
+
+// SCs returns template in SCs text form. Addresses are written as #addr
+func (t *ScTemplate) SCs() string {
+	w := newScsWriter()
+
+	item := func(v ScTemplateValue) string {
+		switch value := v.Value.(type) {
+		case ScAddr:
+			if v.Alias != "" {
+				w.lines = append(w.lines, fmt.Sprintf("// %s = %s", v.Alias, value))
+			}
+			return value.String()
+		case ScType:
+			if v.Alias == "" {
+				name := w.nextAnonymous(!value.IsConst())
+				w.declareType(name, value)
+				return name
+			}
+			w.declareType(v.Alias, value)
+			return v.Alias
+		case string:
+			return w.name(value)
+		default:
+			return fmt.Sprint(value)
+		}
+	}
+
+	for _, triple := range t.Triples {
+		src := item(triple.Source)
+		trg := item(triple.Target)
+
+		connector := "_..>"
+		edgeAlias := triple.Edge.Alias
+		switch value := triple.Edge.Value.(type) {
+		case ScType:
+			connector = scsConnector(value)
+		case ScAddr:
+			if edgeAlias == "" {
+				w.lines = append(w.lines, fmt.Sprintf("// edge %s", value))
+			}
+		case string:
+			// Edge is already defined, so triple can only be commented
+			w.lines = append(w.lines, fmt.Sprintf("// %s = (%s ? %s)", w.name(value), src, trg))
+			continue
+		}
+		w.triple(edgeAlias, src, connector, trg)
+	}
+	return w.String()
+}
+
+// SCs returns construction in SCs text form. Addresses are written as #addr
+func (c *ScConstruction) SCs() string {
+	w := newScsWriter()
+
+	names := make([]string, len(c.Commands))
+	for alias, idx := range c.Aliases {
+		if idx >= 0 && idx < len(names) {
+			names[idx] = alias
+		}
+	}
+
+	ref := func(v interface{}) string {
+		switch value := v.(type) {
+		case ScAddr:
+			return value.String()
+		case string:
+			return w.name(value)
+		default:
+			return fmt.Sprint(value)
+		}
+	}
+
+	for i, cmd := range c.Commands {
+		switch {
+		case cmd.Type.IsNode():
+			name := names[i]
+			if name == "" {
+				name = w.nextAnonymous(false)
+			}
+			w.declareType(name, cmd.Type)
+		case cmd.Type.IsEdge():
+			data := cmd.Data.(map[string]interface{})
+			w.triple(names[i], ref(data["src"]), scsConnector(cmd.Type), ref(data["trg"]))
+		case cmd.Type.IsLink():
+			name := names[i]
+			if name == "" {
+				name = w.nextAnonymous(false)
+			}
+			data := cmd.Data.(map[string]interface{})
+			w.lines = append(w.lines, fmt.Sprintf("%s = [%v];;", name, data["content"]))
+		}
+	}
+	return w.String()
+}
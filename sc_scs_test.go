@@ -0,0 +1,70 @@
+package sc
+
+import "testing"
+
+func TestScTypeString(t *testing.T) {
+	tests := []struct {
+		t        ScType
+		expected string
+	}{
+		{ScType{Value: ScTypeArcPosConstPerm}, "sc_edge_access|sc_const|sc_edge_pos|sc_edge_perm"},
+		{ScType{Value: ScTypeNodeConstClass}, "sc_node_class|sc_const"},
+		{ScType{Value: ScTypeDEdgeVar}, "sc_edge_dcommon|sc_var"},
+		{ScType{}, "sc_unknown"},
+	}
+
+	for _, tt := range tests {
+		if s := tt.t.String(); s != tt.expected {
+			t.Errorf("expected %q for type %d, got %q", tt.expected, tt.t.Value, s)
+		}
+	}
+}
+
+func TestScTemplateResultString(t *testing.T) {
+	result := ScTemplateResult{Addrs: testAddrs(1, 2), Indices: map[string]int{"_b": 1, "_a": 0}}
+	if s := result.String(); s != "{_a: #1, _b: #2}" {
+		t.Errorf("unexpected result string %q", s)
+	}
+}
+
+func TestScTemplateSCs(t *testing.T) {
+	template := &ScTemplate{}
+	template.TripleWithRelation(
+		ScAddr{Value: 5},
+		[]interface{}{ScType{Value: ScTypeDEdgeVar}, "_edge"},
+		[]interface{}{ScType{Value: ScTypeNodeVar}, "_trg"},
+		ScType{Value: ScTypeArcPosVarPerm},
+		ScAddr{Value: 7},
+	)
+
+	expected := "@_edge = (#5 _=> _trg);;\n" +
+		"#7 _-> @_edge;;\n" +
+		"_trg <- sc_node;;"
+	if s := template.SCs(); s != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, s)
+	}
+}
+
+func TestScConstructionSCs(t *testing.T) {
+	construction := &ScConstruction{}
+	if err := construction.CreateNode(ScType{Value: ScTypeNodeConstClass}, "class"); err != nil {
+		t.Fatal(err)
+	}
+	if err := construction.CreateLink(ScType{Value: ScTypeLinkConst}, ScLinkContent{Data: "text", Type: ScLinkContentString}, "link"); err != nil {
+		t.Fatal(err)
+	}
+	if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, "class", "link", "arc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := construction.CreateEdge(ScType{Value: ScTypeArcPosConstPerm}, ScAddr{Value: 3}, "arc", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "link = [text];;\n" +
+		"@arc = (class -> link);;\n" +
+		"#3 -> @arc;;\n" +
+		"class <- sc_node_class;;"
+	if s := construction.SCs(); s != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, s)
+	}
+}
@@ -1,51 +1,140 @@
 package sc
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"sync"
+	"time"
 )
 
-// ScSet represents SC set
+// ScSet represents SC set. It is safe for concurrent use
 type ScSet struct {
 	Client       *ScClient
 	Addr         ScAddr
-	Elements     map[int64]ScAddr
 	OnAdd        func(ScAddr) error
 	OnRemove     func(ScAddr) error
 	OnInitialize func([]ScAddr) error
 	FilterType   *ScType
+	ArcType      ScType
 	AddEvent     *ScEvent
 	RemoveEvent  *ScEvent
+
+	// CoalesceDelay is a time add events are collected for to check their types with one request
+	CoalesceDelay time.Duration
+	// ResyncInterval enables periodic resynchronisation with knowledge base when positive
+	ResyncInterval time.Duration
+	// TrackMemberRemoval listens for removal of every member element. Deleted member is removed
+	// from set without it too, because its membership arc is erased with it. Enable it before
+	// Initialize only if arcs may be left by server, it creates server event for every member
+	TrackMemberRemoval bool
+
+	// Elements are members of set keyed by their membership arcs.
+	//
+	// Deprecated: use Snapshot, Contains and Len. Elements is changed under lock of set,
+	// so it may be read directly only before Initialize and after Close, and must not be changed
+	Elements map[int64]ScAddr
+
+	mu sync.RWMutex
+	// arcs are membership arcs of every member, so members are found without scanning Elements
+	arcs        map[int64][]int64
+	initialized bool
+	pending     []pendingMember
+	changes     []setChange
+	flushTimer  *time.Timer
+	wake        chan struct{}
+	worker      sync.Once
+	done        chan struct{}
+
+	memberListeners map[int64]*ScEventListener
+	closed          bool
+
+	// addBatch and removeBatch receive consecutive changes together instead of OnAdd and OnRemove
+	addBatch    func([]ScAddr) error
+	removeBatch func([]ScAddr) error
+}
+
+// DefaultScSetCoalesceDelay is a default time add events of set are collected for
+const DefaultScSetCoalesceDelay = 20 * time.Millisecond
+
+// scSetRetryDelay is a time set waits for before checking pending members again after failure
+const scSetRetryDelay = time.Second
+
+// pendingMember represents added element waiting for type check
+type pendingMember struct {
+	edge ScAddr
+	item ScAddr
+}
+
+// setChange represents element added to or removed from set waiting for callback
+type setChange struct {
+	item  ScAddr
+	added bool
 }
 
-// NewScSet creates new SC set
+// NewScSet creates new SC set. Callbacks may be nil
 func NewScSet(client *ScClient, addr ScAddr, onInitialize, onAdd, onRemove func([]ScAddr) error, filterType *ScType) (*ScSet, error) {
 	if !addr.IsValid() {
 		return nil, fmt.Errorf("invalid addr of set: %v", addr)
 	}
 
 	set := &ScSet{
-		Client:     client,
-		Addr:       addr,
-		Elements:   make(map[int64]ScAddr),
-		FilterType: filterType,
+		Client:        client,
+		Addr:          addr,
+		FilterType:    filterType,
+		ArcType:       ScType{Value: ScTypeArcPosConstPerm},
+		CoalesceDelay: DefaultScSetCoalesceDelay,
+		Elements:      make(map[int64]ScAddr),
+		arcs:          make(map[int64][]int64),
+		wake:          make(chan struct{}, 1),
+		done:          make(chan struct{}),
+
+		memberListeners: make(map[int64]*ScEventListener),
 	}
 
 	// Adapt callback functions
 	set.OnInitialize = func(addrs []ScAddr) error {
+		if onInitialize == nil {
+			return nil
+		}
 		return onInitialize(addrs)
 	}
 	set.OnAdd = func(addr ScAddr) error {
+		if onAdd == nil {
+			return nil
+		}
 		return onAdd([]ScAddr{addr})
 	}
 	set.OnRemove = func(addr ScAddr) error {
+		if onRemove == nil {
+			return nil
+		}
 		return onRemove([]ScAddr{addr})
 	}
 
 	return set, nil
 }
 
-// Initialize initializes set
-func (s *ScSet) Initialize() error {
+// Initialize subscribes to set changes and iterates existing elements.
+// Set is closed when ctx is cancelled. Set is initialized once, it may be initialized again only after failure
+func (s *ScSet) Initialize(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	switch {
+	case s.closed:
+		s.mu.Unlock()
+		return CommonError(ErrInvalidState, fmt.Sprintf("set %v is closed", s.Addr))
+	case s.initialized:
+		s.mu.Unlock()
+		return CommonError(ErrInvalidState, fmt.Sprintf("set %v is already initialized", s.Addr))
+	}
+	s.initialized = true
+	s.mu.Unlock()
+
 	// Create events for adding and removing elements
 	events, err := s.Client.EventsCreate([]ScEventParams{
 		{
@@ -60,34 +149,259 @@ func (s *ScSet) Initialize() error {
 		},
 	})
 	if err != nil {
+		s.mu.Lock()
+		s.initialized = false
+		s.mu.Unlock()
 		return err
 	}
 
+	s.mu.Lock()
 	s.AddEvent = &events[0]
 	s.RemoveEvent = &events[1]
+	s.mu.Unlock()
 
 	// Iterate existing elements
-	return s.iterateExistingElements()
+	if err := s.iterateExistingElements(); err != nil {
+		s.mu.Lock()
+		s.AddEvent = nil
+		s.RemoveEvent = nil
+		s.initialized = false
+		members := make([]ScAddr, 0, len(s.arcs))
+		for item := range s.arcs {
+			members = append(members, ScAddr{Value: item})
+		}
+		s.setMembersLocked(make(map[int64]ScAddr))
+		s.mu.Unlock()
+
+		// Set is not initialized, so it doesn't listen for anything
+		s.untrackMembers(members)
+		if destroyErr := s.Client.EventsDestroy([]int{events[0].ID, events[1].ID}); destroyErr != nil {
+			log.Printf("Failed to destroy events of set %v: %v", s.Addr, destroyErr)
+		}
+		return err
+	}
+
+	// Changes received during iteration are delivered after OnInitialize
+	s.startWorker()
+	go func() {
+		select {
+		case <-ctx.Done():
+			if err := s.Close(); err != nil {
+				log.Printf("Failed to close set %v: %v", s.Addr, err)
+			}
+		case <-s.done:
+		}
+	}()
+
+	if s.ResyncInterval > 0 {
+		go s.resyncPeriodically(ctx)
+	}
+	return ctx.Err()
 }
 
 func (s *ScSet) onEventAddElement(elAddr, edge, other ScAddr, eventID int) {
-	if _, exists := s.Elements[edge.Value]; !exists {
-		if elAddr.IsValid() {
-			if shouldAppend, _ := s.shouldAppend([]ScAddr{elAddr}); shouldAppend[0] {
-				s.Elements[edge.Value] = elAddr
-				s.OnAdd(elAddr)
+	if !other.IsValid() || s.hasEdge(edge) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.pending = append(s.pending, pendingMember{edge: edge, item: other})
+
+	if s.CoalesceDelay <= 0 {
+		s.wakeWorker()
+	} else if s.flushTimer == nil {
+		s.flushTimer = time.AfterFunc(s.CoalesceDelay, s.wakeWorker)
+	}
+}
+
+func (s *ScSet) onEventRemoveElement(elAddr, edge, other ScAddr, eventID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, member := range s.pending {
+		if member.edge.Equal(edge) {
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			break
+		}
+	}
+	if trg, exists := s.removeMemberLocked(edge.Value); exists {
+		s.changes = append(s.changes, setChange{item: trg})
+		s.wakeWorker()
+	}
+}
+
+// startWorker starts goroutine checking pending members and calling OnAdd and OnRemove.
+// Callbacks are never called concurrently and are called in order of changes
+func (s *ScSet) startWorker() {
+	s.worker.Do(func() {
+		go func() {
+			for {
+				select {
+				case <-s.done:
+					return
+				case <-s.wake:
+				}
+				s.flushPending()
+				s.deliverChanges()
 			}
+		}()
+	})
+	s.wakeWorker()
+}
+
+func (s *ScSet) wakeWorker() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// flushPending checks types of all pending members with one request and adds matching ones.
+// Members removed while types are checked are skipped, on failure members are checked again later
+func (s *ScSet) flushPending() {
+	s.mu.Lock()
+	pending := append([]pendingMember(nil), s.pending...)
+	if s.flushTimer != nil {
+		s.flushTimer.Stop()
+		s.flushTimer = nil
+	}
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	addrs := make([]ScAddr, 0, len(pending)*2)
+	for _, member := range pending {
+		addrs = append(addrs, member.edge, member.item)
+	}
+
+	types, err := s.Client.CheckElements(addrs)
+	if err == nil && len(types) != len(addrs) {
+		err = CommonError(ErrInvalidState, fmt.Sprintf("got %d types for %d elements", len(types), len(addrs)))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to check types of set %v members: %v", s.Addr, err)
+		if s.flushTimer == nil {
+			s.flushTimer = time.AfterFunc(scSetRetryDelay, s.wakeWorker)
+		}
+		return
+	}
+
+	// Members removed from pending by remove events are not added
+	checked := make(map[int64]bool, len(pending))
+	for _, member := range pending {
+		checked[member.edge.Value] = true
+	}
+	present := make(map[int64]bool, len(pending))
+	var rest []pendingMember
+	for _, member := range s.pending {
+		if checked[member.edge.Value] {
+			present[member.edge.Value] = true
+		} else {
+			rest = append(rest, member)
+		}
+	}
+	s.pending = rest
+
+	arcType := s.membershipType()
+	for i, member := range pending {
+		if !present[member.edge.Value] {
+			continue
+		}
+		edgeType, itemType := types[i*2], types[i*2+1]
+		if (edgeType.Value & arcType.Value) != arcType.Value {
+			continue
 		}
+		if s.FilterType != nil && (s.FilterType.Value&itemType.Value) != s.FilterType.Value {
+			continue
+		}
+		if _, exists := s.Elements[member.edge.Value]; exists {
+			continue
+		}
+		s.addMemberLocked(member.edge, member.item)
+		s.changes = append(s.changes, setChange{item: member.item, added: true})
 	}
 }
 
-func (s *ScSet) onEventRemoveElement(elAddr, edge, other ScAddr, eventID int) {
-	if trg, exists := s.Elements[edge.Value]; exists {
-		s.OnRemove(trg)
-		delete(s.Elements, edge.Value)
+// deliverChanges updates tracking of members and calls callbacks of queued changes
+func (s *ScSet) deliverChanges() {
+	s.mu.Lock()
+	changes := s.changes
+	s.changes = nil
+	s.mu.Unlock()
+
+	if len(changes) == 0 {
+		return
+	}
+
+	var added, removed []ScAddr
+	for _, change := range changes {
+		if change.added {
+			added = append(added, change.item)
+		} else {
+			removed = append(removed, change.item)
+		}
+	}
+	s.untrackMembers(removed)
+	s.trackMembers(added)
+
+	for start := 0; start < len(changes); {
+		end := start
+		items := []ScAddr{}
+		for end < len(changes) && changes[end].added == changes[start].added {
+			items = append(items, changes[end].item)
+			end++
+		}
+		if s.isClosed() {
+			return
+		}
+		s.notify(items, changes[start].added)
+		start = end
 	}
 }
 
+// notify calls callbacks of items added to or removed from set
+func (s *ScSet) notify(items []ScAddr, added bool) {
+	switch {
+	case added && s.addBatch != nil:
+		s.addBatch(items)
+	case !added && s.removeBatch != nil:
+		s.removeBatch(items)
+	case added:
+		for _, item := range items {
+			s.OnAdd(item)
+		}
+	default:
+		for _, item := range items {
+			s.OnRemove(item)
+		}
+	}
+}
+
+func (s *ScSet) isClosed() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.closed
+}
+
+func (s *ScSet) hasEdge(edge ScAddr) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, exists := s.Elements[edge.Value]
+	return exists
+}
+
 func (s *ScSet) shouldAppend(addrs []ScAddr) ([]bool, error) {
 	if s.FilterType == nil {
 		result := make([]bool, len(addrs))
@@ -109,54 +423,266 @@ func (s *ScSet) shouldAppend(addrs []ScAddr) ([]bool, error) {
 	return result, nil
 }
 
-func (s *ScSet) iterateExistingElements() error {
+// membershipType returns constant type of membership arcs
+func (s *ScSet) membershipType() ScType {
+	if !s.ArcType.IsValid() {
+		return ScType{Value: ScTypeArcPosConstPerm}
+	}
+	return s.ArcType.AsConst()
+}
+
+// searchMembers searches all membership arcs of set with their targets
+func (s *ScSet) searchMembers() ([]ScTemplateResult, error) {
 	template := &ScTemplate{}
 	template.Triple(
 		s.Addr,
-		[]interface{}{ScType{Value: ScTypeArcPosVarPerm}, "_edge"},
+		[]interface{}{s.membershipType().AsVar(), "_edge"},
 		[]interface{}{ScType{Value: 0}, "_item"},
 	)
+	return s.Client.TemplateSearch(template)
+}
 
-	results, err := s.Client.TemplateSearch(template)
+func (s *ScSet) iterateExistingElements() error {
+	results, err := s.searchMembers()
 	if err != nil {
 		return err
 	}
 
-	var elements []ScAddr
-	for _, result := range results {
-		edge := result.Get("_edge")
-		item := result.Get("_item")
+	items := make([]ScAddr, len(results))
+	for i, result := range results {
+		items[i] = result.Get("_item")
+	}
+
+	// Types of all items are checked with one request
+	shouldAppend, err := s.shouldAppend(items)
+	if err != nil {
+		return err
+	}
 
-		if shouldAppend, _ := s.shouldAppend([]ScAddr{item}); shouldAppend[0] {
-			s.Elements[edge.Value] = item
-			elements = append(elements, item)
+	var elements []ScAddr
+	s.mu.Lock()
+	for i, result := range results {
+		if shouldAppend[i] {
+			s.addMemberLocked(result.Get("_edge"), items[i])
+			elements = append(elements, items[i])
 		}
 	}
+	s.mu.Unlock()
 
+	s.trackMembers(elements)
 	return s.OnInitialize(elements)
 }
 
+// Snapshot returns current elements of set
+func (s *ScSet) Snapshot() []ScAddr {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	elements := make([]ScAddr, 0, len(s.Elements))
+	for _, addr := range s.Elements {
+		elements = append(elements, addr)
+	}
+	return elements
+}
+
+// Contains checks if element is in set
+func (s *ScSet) Contains(addr ScAddr) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.containsLocked(addr)
+}
+
+func (s *ScSet) containsLocked(addr ScAddr) bool {
+	return len(s.arcs[addr.Value]) > 0
+}
+
+// addMemberLocked adds member with its membership arc
+func (s *ScSet) addMemberLocked(edge, item ScAddr) {
+	s.Elements[edge.Value] = item
+	s.arcs[item.Value] = append(s.arcs[item.Value], edge.Value)
+}
+
+// removeMemberLocked removes membership arc and returns member it leads to
+func (s *ScSet) removeMemberLocked(edge int64) (ScAddr, bool) {
+	item, exists := s.Elements[edge]
+	if !exists {
+		return ScAddr{}, false
+	}
+	delete(s.Elements, edge)
+
+	arcs := s.arcs[item.Value]
+	for i, arc := range arcs {
+		if arc == edge {
+			arcs = append(arcs[:i], arcs[i+1:]...)
+			break
+		}
+	}
+	if len(arcs) == 0 {
+		delete(s.arcs, item.Value)
+	} else {
+		s.arcs[item.Value] = arcs
+	}
+	return item, true
+}
+
+// setMembersLocked replaces members with members of elements keyed by membership arcs
+func (s *ScSet) setMembersLocked(elements map[int64]ScAddr) {
+	s.Elements = make(map[int64]ScAddr, len(elements))
+	s.arcs = make(map[int64][]int64, len(elements))
+	for edge, item := range elements {
+		s.addMemberLocked(ScAddr{Value: edge}, item)
+	}
+}
+
+// Len returns number of elements in set
+func (s *ScSet) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.Elements)
+}
+
+// Close destroys set events. Set can't be initialized again after closing
+func (s *ScSet) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	if s.flushTimer != nil {
+		s.flushTimer.Stop()
+		s.flushTimer = nil
+	}
+	s.pending = nil
+	s.changes = nil
+
+	var eventIDs []int
+	for _, event := range []*ScEvent{s.AddEvent, s.RemoveEvent} {
+		if event != nil && event.IsValid() {
+			eventIDs = append(eventIDs, event.ID)
+		}
+	}
+	s.AddEvent = nil
+	s.RemoveEvent = nil
+
+	memberListeners := make([]*ScEventListener, 0, len(s.memberListeners))
+	for _, listener := range s.memberListeners {
+		memberListeners = append(memberListeners, listener)
+	}
+	s.memberListeners = make(map[int64]*ScEventListener)
+	s.mu.Unlock()
+
+	if err := s.Client.closeListeners(memberListeners); err != nil {
+		log.Printf("Failed to stop tracking set %v members: %v", s.Addr, err)
+	}
+
+	if len(eventIDs) == 0 {
+		return nil
+	}
+	return s.Client.EventsDestroy(eventIDs)
+}
+
 // AddItem adds item to set
 func (s *ScSet) AddItem(addr ScAddr) (bool, error) {
-	template := &ScTemplate{}
-	template.Triple(
-		s.Addr,
-		[]interface{}{ScType{Value: ScTypeArcPosVarPerm}, "_edge"},
-		addr,
-	)
+	if !addr.IsValid() {
+		return false, nil
+	}
+	if _, err := s.AddItems([]ScAddr{addr}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-	results, err := s.Client.TemplateSearch(template)
+// AddItems adds items missing in set with one construction. Returns number of added items
+func (s *ScSet) AddItems(addrs []ScAddr) (int, error) {
+	arcs, err := s.membershipArcs(addrs)
 	if err != nil {
-		return false, err
+		return 0, err
+	}
+
+	construction := &ScConstruction{}
+	arcType := s.membershipType()
+	added := make(map[int64]bool, len(arcs))
+	for _, addr := range addrs {
+		if existing, valid := arcs[addr.Value]; !valid || len(existing) > 0 || added[addr.Value] {
+			continue
+		}
+		added[addr.Value] = true
+		if err := construction.CreateEdge(arcType, s.Addr, addr, ""); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(construction.Commands) == 0 {
+		return 0, nil
+	}
+	if _, err := s.Client.CreateElements(construction); err != nil {
+		return 0, err
+	}
+	return len(construction.Commands), nil
+}
+
+// RemoveItem removes membership arcs of item from set
+func (s *ScSet) RemoveItem(addr ScAddr) (bool, error) {
+	removed, err := s.RemoveItems([]ScAddr{addr})
+	return removed > 0, err
+}
+
+// RemoveItems removes membership arcs of items with one delete request. Returns number of removed arcs
+func (s *ScSet) RemoveItems(addrs []ScAddr) (int, error) {
+	arcs, err := s.membershipArcs(addrs)
+	if err != nil {
+		return 0, err
+	}
+
+	var edges []ScAddr
+	for _, itemArcs := range arcs {
+		edges = append(edges, itemArcs...)
+	}
+
+	if len(edges) == 0 {
+		return 0, nil
+	}
+	deleted, err := s.Client.DeleteElements(edges)
+	if err != nil {
+		return 0, err
 	}
+	if !deleted {
+		return 0, errors.New("failed to delete membership arcs")
+	}
+	return len(edges), nil
+}
 
-	if len(results) == 0 {
-		genResult, err := s.Client.TemplateGenerate(template, map[string]ScAddr{})
+// membershipArcs searches membership arcs of set to each of valid items.
+// Only requested items are searched, searches are sent without waiting for each other
+func (s *ScSet) membershipArcs(items []ScAddr) (map[int64][]ScAddr, error) {
+	var unique []ScAddr
+	var requests []<-chan *ScTemplateResultSet
+	arcs := make(map[int64][]ScAddr, len(items))
+	for _, item := range items {
+		if _, exists := arcs[item.Value]; exists || !item.IsValid() {
+			continue
+		}
+		arcs[item.Value] = nil
+
+		template := &ScTemplate{}
+		template.Triple(s.Addr, []interface{}{s.membershipType().AsVar(), "_edge"}, item)
+		request, err := s.Client.sendTemplateSearch(template, nil)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
-		return genResult != nil, nil
+		unique = append(unique, item)
+		requests = append(requests, request)
 	}
 
-	return true, nil
+	for i, request := range requests {
+		set, err := waitTemplateSearch(request)
+		if err != nil {
+			return nil, err
+		}
+		arcs[unique[i].Value] = set.Column("_edge")
+	}
+	return arcs, nil
 }
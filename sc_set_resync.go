@@ -0,0 +1,164 @@
+package sc
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Resync searches current members of set, updates elements and queues OnAdd and OnRemove for the difference.
+// Callbacks are called by goroutine of set like callbacks of events
+func (s *ScSet) Resync(ctx context.Context) error {
+	results, err := s.searchMembers()
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	items := make([]ScAddr, len(results))
+	for i, result := range results {
+		items[i] = result.Get("_item")
+	}
+
+	shouldAppend, err := s.shouldAppend(items)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	actual := make(map[int64]ScAddr, len(results))
+	for i, result := range results {
+		if shouldAppend[i] {
+			actual[result.Get("_edge").Value] = items[i]
+		}
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	for edge, item := range s.Elements {
+		if _, exists := actual[edge]; !exists {
+			s.changes = append(s.changes, setChange{item: item})
+		}
+	}
+	for edge, item := range actual {
+		if _, exists := s.Elements[edge]; !exists {
+			s.changes = append(s.changes, setChange{item: item, added: true})
+		}
+	}
+	s.setMembersLocked(actual)
+	s.mu.Unlock()
+
+	s.startWorker()
+	return nil
+}
+
+// resyncPeriodically resyncs set every ResyncInterval until set is closed
+func (s *ScSet) resyncPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(s.ResyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.Resync(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Failed to resync set %v: %v", s.Addr, err)
+			}
+		}
+	}
+}
+
+// trackMembers listens for removal of member elements when TrackMemberRemoval is set.
+// Events of all members are created with one request
+func (s *ScSet) trackMembers(items []ScAddr) {
+	if !s.TrackMemberRemoval {
+		return
+	}
+
+	var params []listenerParams
+	s.mu.Lock()
+	for _, item := range items {
+		if _, tracked := s.memberListeners[item.Value]; tracked {
+			continue
+		}
+		item := item
+		params = append(params, listenerParams{
+			addr:      item,
+			eventType: ScEventRemoveElement,
+			callback: func(elAddr, edge, other ScAddr, eventID int) {
+				s.onMemberRemoved(item)
+			},
+		})
+	}
+	s.mu.Unlock()
+	if len(params) == 0 {
+		return
+	}
+
+	listeners, err := s.Client.addListeners(params)
+	if err != nil {
+		log.Printf("Failed to track removal of set %v members: %v", s.Addr, err)
+		return
+	}
+
+	var extra []*ScEventListener
+	s.mu.Lock()
+	for i, listener := range listeners {
+		item := params[i].addr
+		if _, tracked := s.memberListeners[item.Value]; tracked || s.closed {
+			extra = append(extra, listener)
+			continue
+		}
+		s.memberListeners[item.Value] = listener
+	}
+	s.mu.Unlock()
+
+	if err := s.Client.closeListeners(extra); err != nil {
+		log.Printf("Failed to stop tracking set %v members: %v", s.Addr, err)
+	}
+}
+
+// untrackMembers stops listening for removal of elements which are not members anymore
+func (s *ScSet) untrackMembers(items []ScAddr) {
+	var listeners []*ScEventListener
+	s.mu.Lock()
+	for _, item := range items {
+		if s.containsLocked(item) {
+			continue
+		}
+		if listener, tracked := s.memberListeners[item.Value]; tracked {
+			delete(s.memberListeners, item.Value)
+			listeners = append(listeners, listener)
+		}
+	}
+	s.mu.Unlock()
+
+	if err := s.Client.closeListeners(listeners); err != nil {
+		log.Printf("Failed to stop tracking set %v members: %v", s.Addr, err)
+	}
+}
+
+// onMemberRemoved removes deleted element from set
+func (s *ScSet) onMemberRemoved(item ScAddr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	arcs := s.arcs[item.Value]
+	for _, edge := range append([]int64(nil), arcs...) {
+		s.removeMemberLocked(edge)
+	}
+	if len(arcs) > 0 {
+		s.changes = append(s.changes, setChange{item: item})
+		s.wakeWorker()
+	}
+}
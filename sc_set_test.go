@@ -0,0 +1,355 @@
+package sc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sc "github.com/temapriemnik/go-sc-client"
+	"github.com/temapriemnik/go-sc-client/agenttest"
+)
+
+func TestScSetInitializeFailureDestroysEvents(t *testing.T) {
+	kit := agenttest.New(t)
+	addrs := kit.LoadSCs(`failing_set -> ..member;;`)
+	setAddr := addrs["failing_set"]
+
+	var added atomic.Int64
+	set, err := sc.NewScSet(kit.Client, setAddr, func([]sc.ScAddr) error {
+		return errors.New("initialization failed")
+	}, func([]sc.ScAddr) error {
+		added.Add(1)
+		return nil
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set.CoalesceDelay = 0
+
+	if err := set.Initialize(context.Background()); err == nil {
+		t.Fatal("expected initialization error")
+	}
+	if set.AddEvent != nil || set.RemoveEvent != nil || set.Len() != 0 {
+		t.Error("failed set keeps events or elements")
+	}
+
+	// Wait for event of new member with another listener, set must not receive it
+	received := make(chan struct{}, 1)
+	listener, err := kit.Client.AddListener(setAddr, sc.ScEventAddOutgoingEdge, func(elAddr, edge, other sc.ScAddr, eventID int) {
+		received <- struct{}{}
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	kit.LoadSCs(`failing_set -> ..other;;`)
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("event is not received")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if added.Load() != 0 {
+		t.Error("set of failed initialization received event")
+	}
+}
+
+func TestScSetInitializeOnce(t *testing.T) {
+	kit := agenttest.New(t)
+	setAddr := kit.LoadSCs(`initialized_set -> ..member;;`)["initialized_set"]
+
+	set, err := sc.NewScSet(kit.Client, setAddr, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Initialize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Initialize(context.Background()); err == nil || !strings.HasPrefix(err.Error(), sc.ErrInvalidState.Error()) {
+		t.Errorf("expected invalid state error, got %v", err)
+	}
+	if count := kit.Client.ListenersCount(setAddr, sc.ScEventAddOutgoingEdge); count != 0 {
+		t.Errorf("second initialization added %d listeners", count)
+	}
+
+	if err := set.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Initialize(context.Background()); err == nil {
+		t.Error("closed set is initialized again")
+	}
+}
+
+func TestScSetContainsMemberWithSeveralArcs(t *testing.T) {
+	kit := agenttest.New(t)
+	addrs := kit.LoadSCs(`
+		counted_set -> ..member;;
+		@second = (counted_set -> ..member);;
+	`)
+
+	set, err := sc.NewScSet(kit.Client, addrs["counted_set"], nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set.CoalesceDelay = 0
+	if err := set.Initialize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer set.Close()
+
+	if set.Len() != 2 || !set.Contains(addrs["..member"]) {
+		t.Fatalf("unexpected set of %d arcs", set.Len())
+	}
+	if _, err := kit.Client.DeleteElements([]sc.ScAddr{addrs["@second"]}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return set.Len() == 1 })
+	if !set.Contains(addrs["..member"]) {
+		t.Error("member with arc left is removed")
+	}
+
+	if _, err := set.RemoveItem(addrs["..member"]); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return set.Len() == 0 })
+	if set.Contains(addrs["..member"]) {
+		t.Error("removed member is contained")
+	}
+}
+
+func TestScSetAddAndRemoveItems(t *testing.T) {
+	kit := agenttest.New(t)
+	addrs := kit.LoadSCs(`
+		items_set -> ..member;;
+		other_set -> ..other;;
+		..free <- sc_node;;
+	`)
+	setAddr, member, free := addrs["items_set"], addrs["..member"], addrs["..free"]
+
+	set, err := sc.NewScSet(kit.Client, setAddr, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	added, err := set.AddItems([]sc.ScAddr{member, free, free, {}})
+	if err != nil {
+		t.Fatalf("failed to add items: %v", err)
+	}
+	if added != 1 {
+		t.Errorf("expected only missing item to be added once, added %d", added)
+	}
+
+	removed, err := set.RemoveItems([]sc.ScAddr{member, free, addrs["..other"]})
+	if err != nil {
+		t.Fatalf("failed to remove items: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("expected 2 membership arcs to be removed, removed %d", removed)
+	}
+
+	template := &sc.ScTemplate{}
+	template.Triple(addrs["other_set"], sc.ScType{Value: sc.ScTypeArcPosVarPerm}, addrs["..other"])
+	kit.AssertTemplateExists(template, nil)
+	template = &sc.ScTemplate{}
+	template.Triple(setAddr, sc.ScType{Value: sc.ScTypeArcPosVarPerm}, sc.ScType{Value: sc.ScTypeNodeVar})
+	kit.AssertNoMatch(template, nil)
+}
+
+func TestScSetCallbacksAreSerialized(t *testing.T) {
+	kit := agenttest.New(t)
+	setAddr := kit.Keynode("serialized_set", sc.ScTypeNodeConst)
+
+	var running, overlaps atomic.Int64
+	var mu sync.Mutex
+	added := make(map[int64]bool)
+	removed := make(map[int64]bool)
+	callback := func(isAdd bool) func([]sc.ScAddr) error {
+		return func(items []sc.ScAddr) error {
+			if running.Add(1) > 1 {
+				overlaps.Add(1)
+			}
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			for _, item := range items {
+				if isAdd {
+					added[item.Value] = true
+				} else if !added[item.Value] {
+					t.Errorf("%v is removed before it is added", item)
+				} else {
+					removed[item.Value] = true
+				}
+			}
+			mu.Unlock()
+			running.Add(-1)
+			return nil
+		}
+	}
+
+	set, err := sc.NewScSet(kit.Client, setAddr, nil, callback(true), callback(false), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set.CoalesceDelay = time.Millisecond
+	if err := set.Initialize(context.Background()); err != nil {
+		t.Fatalf("failed to initialize set: %v", err)
+	}
+	defer set.Close()
+
+	const count = 20
+	construction := &sc.ScConstruction{}
+	for i := 0; i < count; i++ {
+		if err := construction.CreateNode(sc.ScType{Value: sc.ScTypeNodeConst}, fmt.Sprintf("node%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	nodes := kit.Construct(construction)
+	for _, node := range nodes {
+		if _, err := set.AddItem(node); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := set.RemoveItem(node); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := set.AddItems(nodes); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool { return set.Len() == count })
+	if _, err := set.RemoveItems(nodes); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(removed) == count
+	})
+	if overlaps.Load() > 0 {
+		t.Errorf("callbacks overlapped %d times", overlaps.Load())
+	}
+}
+
+func TestScSetSkipsMemberRemovedBeforeFlush(t *testing.T) {
+	kit := agenttest.New(t)
+	setAddr := kit.Keynode("coalesced_set", sc.ScTypeNodeConst)
+
+	var added atomic.Int64
+	set, err := sc.NewScSet(kit.Client, setAddr, nil, func([]sc.ScAddr) error {
+		added.Add(1)
+		return nil
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set.CoalesceDelay = 200 * time.Millisecond
+	if err := set.Initialize(context.Background()); err != nil {
+		t.Fatalf("failed to initialize set: %v", err)
+	}
+	defer set.Close()
+
+	removedMember := kit.LoadSCs(`coalesced_set -> ..removed;;`)["..removed"]
+	keptMember := kit.LoadSCs(`coalesced_set -> ..kept;;`)["..kept"]
+	if _, err := set.RemoveItem(removedMember); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool { return set.Len() == 1 })
+	time.Sleep(50 * time.Millisecond)
+	if !set.Contains(keptMember) || set.Contains(removedMember) || added.Load() != 1 {
+		t.Errorf("unexpected members %v, added %d", set.Snapshot(), added.Load())
+	}
+}
+
+// waitFor waits until condition is true
+func waitFor(tb testing.TB, condition func() bool) {
+	tb.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			tb.Fatal("condition is not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestScSetTracksDeletedMembers(t *testing.T) {
+	kit := agenttest.New(t)
+	addrs := kit.LoadSCs(`tracked_set -> ..first; ..second; ..third;;`)
+	members := []sc.ScAddr{addrs["..first"], addrs["..second"], addrs["..third"]}
+
+	var removed atomic.Int64
+	set, err := sc.NewScSet(kit.Client, addrs["tracked_set"], nil, nil, func([]sc.ScAddr) error {
+		removed.Add(1)
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set.TrackMemberRemoval = true
+	if err := set.Initialize(context.Background()); err != nil {
+		t.Fatalf("failed to initialize set: %v", err)
+	}
+	for _, member := range members {
+		if count := kit.Client.ListenersCount(member, sc.ScEventRemoveElement); count != 1 {
+			t.Errorf("expected member %v to be tracked, listeners: %d", member, count)
+		}
+	}
+
+	if _, err := kit.Client.DeleteElements(members[:1]); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return removed.Load() == 1 && set.Len() == 2 })
+	waitFor(t, func() bool { return kit.Client.ListenersCount(members[0], sc.ScEventRemoveElement) == 0 })
+
+	if err := set.Close(); err != nil {
+		t.Fatalf("failed to close set: %v", err)
+	}
+	for _, member := range members {
+		if count := kit.Client.ListenersCount(member, sc.ScEventRemoveElement); count != 0 {
+			t.Errorf("member %v is still tracked after close", member)
+		}
+	}
+	if removed.Load() != 1 {
+		t.Errorf("expected one removal, got %d", removed.Load())
+	}
+}
+
+func TestScSetRemovesDeletedMembersWithoutTracking(t *testing.T) {
+	kit := agenttest.New(t)
+	addrs := kit.LoadSCs(`untracked_set -> ..first; ..second;;`)
+
+	var removed atomic.Int64
+	set, err := sc.NewScSet(kit.Client, addrs["untracked_set"], nil, nil, func([]sc.ScAddr) error {
+		removed.Add(1)
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.TrackMemberRemoval {
+		t.Error("member removal should not be tracked by default")
+	}
+	if err := set.Initialize(context.Background()); err != nil {
+		t.Fatalf("failed to initialize set: %v", err)
+	}
+	defer set.Close()
+	if count := kit.Client.ListenersCount(addrs["..first"], sc.ScEventRemoveElement); count != 0 {
+		t.Errorf("member is tracked by %d listeners", count)
+	}
+
+	// Membership arc is erased with member
+	if _, err := kit.Client.DeleteElements([]sc.ScAddr{addrs["..first"]}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return removed.Load() == 1 && set.Len() == 1 })
+	if set.Contains(addrs["..first"]) {
+		t.Error("deleted member is left in set")
+	}
+}
@@ -0,0 +1,118 @@
+package sc
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultSubscriptionBuffer is a number of events subscription keeps before dropping new ones
+const DefaultSubscriptionBuffer = 64
+
+// ScEventData represents event received by subscription
+type ScEventData struct {
+	Element    ScAddr
+	Edge       ScAddr
+	Other      ScAddr
+	Type       ScEventType
+	Payload    ScEventPayload
+	ReceivedAt time.Time
+}
+
+// Subscription delivers events of one element through channel
+type Subscription struct {
+	listener *ScEventListener
+	events   chan ScEventData
+	done     chan struct{}
+	dropped  uint64
+	mu       sync.Mutex
+	closed   bool
+}
+
+// Subscribe listens for element event and delivers it through subscription channel.
+// Listener is removed when ctx is cancelled or Close is called
+func (c *ScClient) Subscribe(ctx context.Context, addr ScAddr, eventType ScEventType) (*Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		events: make(chan ScEventData, DefaultSubscriptionBuffer),
+		done:   make(chan struct{}),
+	}
+
+	listener, err := c.AddListener(addr, eventType, func(elAddr, edge, other ScAddr, eventID int) {
+		sub.push(ScEventData{
+			Element:    elAddr,
+			Edge:       edge,
+			Other:      other,
+			Type:       eventType,
+			Payload:    DecodeScEvent(eventType, elAddr, edge, other),
+			ReceivedAt: time.Now(),
+		})
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	sub.listener = listener
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			if err := sub.Close(); err != nil {
+				log.Printf("Failed to close subscription of %s on %s: %v", eventType, addr, err)
+			}
+		case <-sub.done:
+		}
+	}()
+
+	return sub, nil
+}
+
+// push delivers event without blocking, event is dropped when channel is full
+func (s *Subscription) push(data ScEventData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.events <- data:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+// Events returns channel of received events. Channel is closed when subscription is closed
+func (s *Subscription) Events() <-chan ScEventData {
+	return s.events
+}
+
+// Dropped returns number of events dropped because of channel overflow
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Done returns channel closed when subscription is closed
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close removes event listener and closes events channel
+func (s *Subscription) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.events)
+	close(s.done)
+	s.mu.Unlock()
+
+	return s.listener.Close()
+}
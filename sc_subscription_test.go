@@ -0,0 +1,123 @@
+package sc_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	sc "github.com/temapriemnik/go-sc-client"
+	"github.com/temapriemnik/go-sc-client/agenttest"
+)
+
+// addMembers creates nodes with arcs from set, returns addrs of node and arc pairs
+func addMembers(tb testing.TB, kit *agenttest.Kit, set sc.ScAddr, count int) []sc.ScAddr {
+	tb.Helper()
+
+	construction := &sc.ScConstruction{}
+	for i := 0; i < count; i++ {
+		node := fmt.Sprintf("node%d", i)
+		if err := construction.CreateNode(sc.ScType{Value: sc.ScTypeNodeConst}, node); err != nil {
+			tb.Fatal(err)
+		}
+		if err := construction.CreateEdge(sc.ScType{Value: sc.ScTypeArcPosConstPerm}, set, node, ""); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return kit.Construct(construction)
+}
+
+func TestSubscriptionDeliversEvents(t *testing.T) {
+	kit := agenttest.New(t)
+	set := kit.Keynode("subscribed_set", sc.ScTypeNodeConst)
+
+	sub, err := kit.Client.Subscribe(context.Background(), set, sc.ScEventAddOutgoingEdge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	created := addMembers(t, kit, set, 1)
+
+	select {
+	case event := <-sub.Events():
+		payload, ok := event.Payload.(sc.ScEventConnectorPayload)
+		if !event.Element.Equal(set) || !event.Edge.Equal(created[1]) || !event.Other.Equal(created[0]) || event.Type != sc.ScEventAddOutgoingEdge {
+			t.Errorf("unexpected event %+v", event)
+		}
+		if !ok || !payload.Connector.Equal(created[1]) || !payload.Target.Equal(created[0]) || event.ReceivedAt.IsZero() {
+			t.Errorf("unexpected payload %+v", event.Payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event is not delivered")
+	}
+}
+
+func TestSubscriptionCloseWithPendingEvents(t *testing.T) {
+	kit := agenttest.New(t)
+	set := kit.Keynode("subscribed_set", sc.ScTypeNodeConst)
+
+	sub, err := kit.Client.Subscribe(context.Background(), set, sc.ScEventAddOutgoingEdge)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Events over buffer are dropped while nobody reads
+	const extra = 10
+	addMembers(t, kit, set, sc.DefaultSubscriptionBuffer+extra)
+	waitFor(t, func() bool { return sub.Dropped() == extra })
+	if len(sub.Events()) != sc.DefaultSubscriptionBuffer {
+		t.Errorf("expected full buffer, got %d events", len(sub.Events()))
+	}
+
+	// Pending events are still read after close, then channel is closed
+	if err := sub.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.Close(); err != nil {
+		t.Errorf("second close failed: %v", err)
+	}
+	read := 0
+	for range sub.Events() {
+		read++
+	}
+	if read != sc.DefaultSubscriptionBuffer {
+		t.Errorf("read %d pending events", read)
+	}
+	select {
+	case <-sub.Done():
+	default:
+		t.Error("done is not closed")
+	}
+
+	// Events after close are not delivered and do not panic
+	addMembers(t, kit, set, 1)
+	if _, err := kit.Client.CheckElements([]sc.ScAddr{set}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSubscriptionClosedByContext(t *testing.T) {
+	kit := agenttest.New(t)
+	set := kit.Keynode("subscribed_set", sc.ScTypeNodeConst)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub, err := kit.Client.Subscribe(ctx, set, sc.ScEventAddOutgoingEdge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	select {
+	case <-sub.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription is not closed by context")
+	}
+	if _, open := <-sub.Events(); open {
+		t.Error("events channel is not closed")
+	}
+
+	if _, err := kit.Client.Subscribe(ctx, set, sc.ScEventAddOutgoingEdge); err == nil {
+		t.Error("subscription is created with cancelled context")
+	}
+}
@@ -2,6 +2,8 @@ package sc
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 )
 
 // ScTemplateValue represents template value
@@ -51,6 +53,8 @@ func (t *ScTemplate) TripleWithRelation(param1, param2, param3, param4, param5 i
 
 func (t *ScTemplate) splitTemplateParam(param interface{}) ScTemplateValue {
 	switch v := param.(type) {
+	case ScTemplateValue:
+		return v
 	case []interface{}:
 		if len(v) != 2 {
 			panic("invalid number of values for replacement. Use [ScType | ScAddr, string]")
@@ -110,3 +114,26 @@ func (r ScTemplateResult) ForEachTriple(f func(src, edge, trg ScAddr)) {
 		f(r.Addrs[i], r.Addrs[i+1], r.Addrs[i+2])
 	}
 }
+
+// String returns aliases of result with their addresses
+func (r ScTemplateResult) String() string {
+	if len(r.Indices) == 0 {
+		return fmt.Sprint(r.Addrs)
+	}
+
+	aliases := make([]string, 0, len(r.Indices))
+	for alias := range r.Indices {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	parts := make([]string, len(aliases))
+	for i, alias := range aliases {
+		value := "?"
+		if idx := r.Indices[alias]; idx >= 0 && idx < len(r.Addrs) {
+			value = r.Addrs[idx].String()
+		}
+		parts[i] = fmt.Sprintf("%s: %s", alias, value)
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
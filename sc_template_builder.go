@@ -0,0 +1,143 @@
+package sc
+
+import (
+	"fmt"
+)
+
+// ScTemplateItemKind represents kind of typed template item
+type ScTemplateItemKind int
+
+const (
+	ScTemplateItemVar ScTemplateItemKind = iota
+	ScTemplateItemAddr
+	ScTemplateItemRef
+)
+
+// ScTemplateItem represents typed template item
+type ScTemplateItem struct {
+	Kind  ScTemplateItemKind
+	Type  ScType
+	Addr  ScAddr
+	Ref   string
+	Alias string
+}
+
+// As returns copy of item with alias assigned
+func (i ScTemplateItem) As(alias string) ScTemplateItem {
+	i.Alias = alias
+	return i
+}
+
+// toValue converts item to untyped template value
+func (i ScTemplateItem) toValue() ScTemplateValue {
+	switch i.Kind {
+	case ScTemplateItemAddr:
+		return ScTemplateValue{Value: i.Addr, Alias: i.Alias}
+	case ScTemplateItemRef:
+		return ScTemplateValue{Value: i.Ref}
+	default:
+		return ScTemplateValue{Value: i.Type, Alias: i.Alias}
+	}
+}
+
+// ScTemplateItems creates typed template items
+type ScTemplateItems struct{}
+
+// T is used to create typed template items: T.Var(t).As("_x"), T.Addr(a), T.Ref("_x")
+var T ScTemplateItems
+
+// Var creates variable item of specified type
+func (ScTemplateItems) Var(t ScType) ScTemplateItem {
+	return ScTemplateItem{Kind: ScTemplateItemVar, Type: t}
+}
+
+// Addr creates item with fixed address
+func (ScTemplateItems) Addr(a ScAddr) ScTemplateItem {
+	return ScTemplateItem{Kind: ScTemplateItemAddr, Addr: a}
+}
+
+// Ref creates reference to item defined by alias
+func (ScTemplateItems) Ref(alias string) ScTemplateItem {
+	return ScTemplateItem{Kind: ScTemplateItemRef, Ref: alias}
+}
+
+// ScTemplateBuilder builds template from typed items
+type ScTemplateBuilder struct {
+	triples [][3]ScTemplateItem
+}
+
+// NewScTemplateBuilder creates new template builder
+func NewScTemplateBuilder() *ScTemplateBuilder {
+	return &ScTemplateBuilder{}
+}
+
+// Triple adds a triple to builder
+func (b *ScTemplateBuilder) Triple(src, edge, trg ScTemplateItem) *ScTemplateBuilder {
+	b.triples = append(b.triples, [3]ScTemplateItem{src, edge, trg})
+	return b
+}
+
+// TripleWithRelation adds a triple with relation to builder
+func (b *ScTemplateBuilder) TripleWithRelation(src, edge, trg, relEdge, rel ScTemplateItem) *ScTemplateBuilder {
+	if edge.Alias == "" && edge.Kind != ScTemplateItemRef {
+		edge.Alias = fmt.Sprintf("edge_1_%d", len(b.triples))
+	}
+
+	edgeRef := edge.Ref
+	if edge.Kind != ScTemplateItemRef {
+		edgeRef = edge.Alias
+	}
+
+	b.Triple(src, edge, trg)
+	b.Triple(rel, relEdge, T.Ref(edgeRef))
+	return b
+}
+
+// Build validates items and returns template
+func (b *ScTemplateBuilder) Build() (*ScTemplate, error) {
+	// References are checked against aliases of earlier triples only
+	defined := make(map[string]bool)
+	template := &ScTemplate{}
+	for i, triple := range b.triples {
+		for _, item := range triple {
+			if err := b.checkItem(i, item, defined); err != nil {
+				return nil, err
+			}
+		}
+		for _, item := range triple {
+			if item.Kind != ScTemplateItemRef && item.Alias != "" {
+				defined[item.Alias] = true
+			}
+		}
+
+		template.Triples = append(template.Triples, ScTemplateTriple{
+			Source: triple[0].toValue(),
+			Edge:   triple[1].toValue(),
+			Target: triple[2].toValue(),
+		})
+	}
+	return template, nil
+}
+
+func (b *ScTemplateBuilder) checkItem(tripleIndex int, item ScTemplateItem, defined map[string]bool) error {
+	switch item.Kind {
+	case ScTemplateItemVar:
+		if item.Type.IsConst() {
+			return CommonError(ErrInvalidType, fmt.Sprintf("const type %d is used in variable position of triple %d", item.Type.Value, tripleIndex))
+		}
+	case ScTemplateItemAddr:
+		if !item.Addr.IsValid() {
+			return CommonError(ErrInvalidParameters, fmt.Sprintf("invalid addr in triple %d", tripleIndex))
+		}
+	case ScTemplateItemRef:
+		if item.Alias != "" {
+			return CommonError(ErrInvalidAlias, fmt.Sprintf("reference %q can't define alias %q in triple %d", item.Ref, item.Alias, tripleIndex))
+		}
+		if !defined[item.Ref] {
+			return CommonError(ErrInvalidAlias, fmt.Sprintf("alias %q referenced in triple %d is not defined before", item.Ref, tripleIndex))
+		}
+	default:
+		return CommonError(ErrInvalidParameters, fmt.Sprintf("unknown item kind in triple %d", tripleIndex))
+	}
+	return nil
+}
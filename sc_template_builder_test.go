@@ -0,0 +1,130 @@
+package sc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScTemplateBuilderBuild(t *testing.T) {
+	src := ScAddr{Value: 10}
+	rel := ScAddr{Value: 20}
+
+	template, err := NewScTemplateBuilder().
+		TripleWithRelation(
+			T.Addr(src),
+			T.Var(ScType{Value: ScTypeDEdgeVar}).As("_edge"),
+			T.Var(ScType{Value: ScTypeNodeVar}).As("_trg"),
+			T.Var(ScType{Value: ScTypeArcPosVarPerm}),
+			T.Addr(rel),
+		).
+		Triple(T.Ref("_trg"), T.Var(ScType{Value: ScTypeArcPosVarPerm}), T.Var(ScType{Value: ScTypeNodeVar}).As("_el")).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build template: %v", err)
+	}
+
+	if len(template.Triples) != 3 {
+		t.Fatalf("expected 3 triples, got %d", len(template.Triples))
+	}
+	first := template.Triples[0]
+	if first.Source.Value != src || first.Edge.Alias != "_edge" || first.Target.Alias != "_trg" {
+		t.Errorf("unexpected first triple %+v", first)
+	}
+	relation := template.Triples[1]
+	if relation.Source.Value != rel || relation.Target.Value != "_edge" {
+		t.Errorf("unexpected relation triple %+v", relation)
+	}
+	if ref := template.Triples[2].Source; ref.Value != "_trg" || ref.Alias != "" {
+		t.Errorf("unexpected reference %+v", ref)
+	}
+}
+
+func TestScTemplateBuilderGeneratesEdgeAlias(t *testing.T) {
+	template, err := NewScTemplateBuilder().
+		TripleWithRelation(
+			T.Addr(ScAddr{Value: 1}),
+			T.Var(ScType{Value: ScTypeDEdgeVar}),
+			T.Var(ScType{Value: ScTypeNodeVar}),
+			T.Var(ScType{Value: ScTypeArcPosVarPerm}),
+			T.Addr(ScAddr{Value: 2}),
+		).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build template: %v", err)
+	}
+
+	alias := template.Triples[0].Edge.Alias
+	if alias == "" {
+		t.Fatal("expected generated edge alias")
+	}
+	if template.Triples[1].Target.Value != alias {
+		t.Errorf("expected relation arc to %q, got %v", alias, template.Triples[1].Target.Value)
+	}
+}
+
+func TestScTemplateBuilderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *ScTemplateBuilder
+		err     error
+	}{
+		{
+			name: "const type in var position",
+			builder: NewScTemplateBuilder().Triple(
+				T.Addr(ScAddr{Value: 1}),
+				T.Var(ScType{Value: ScTypeArcPosConstPerm}),
+				T.Var(ScType{Value: ScTypeNodeVar}),
+			),
+			err: ErrInvalidType,
+		},
+		{
+			name: "invalid addr",
+			builder: NewScTemplateBuilder().Triple(
+				T.Addr(ScAddr{}),
+				T.Var(ScType{Value: ScTypeArcPosVarPerm}),
+				T.Var(ScType{Value: ScTypeNodeVar}),
+			),
+			err: ErrInvalidParameters,
+		},
+		{
+			name: "reference with alias",
+			builder: NewScTemplateBuilder().
+				Triple(T.Addr(ScAddr{Value: 1}), T.Var(ScType{Value: ScTypeArcPosVarPerm}), T.Var(ScType{Value: ScTypeNodeVar}).As("_x")).
+				Triple(T.Ref("_x").As("_y"), T.Var(ScType{Value: ScTypeArcPosVarPerm}), T.Var(ScType{Value: ScTypeNodeVar})),
+			err: ErrInvalidAlias,
+		},
+		{
+			name: "undefined reference",
+			builder: NewScTemplateBuilder().Triple(
+				T.Ref("_missing"),
+				T.Var(ScType{Value: ScTypeArcPosVarPerm}),
+				T.Var(ScType{Value: ScTypeNodeVar}),
+			),
+			err: ErrInvalidAlias,
+		},
+		{
+			name: "forward reference",
+			builder: NewScTemplateBuilder().
+				Triple(T.Addr(ScAddr{Value: 1}), T.Var(ScType{Value: ScTypeArcPosVarPerm}), T.Ref("_x")).
+				Triple(T.Addr(ScAddr{Value: 2}), T.Var(ScType{Value: ScTypeArcPosVarPerm}), T.Var(ScType{Value: ScTypeNodeVar}).As("_x")),
+			err: ErrInvalidAlias,
+		},
+		{
+			name: "reference within defining triple",
+			builder: NewScTemplateBuilder().Triple(
+				T.Var(ScType{Value: ScTypeNodeVar}).As("_x"),
+				T.Var(ScType{Value: ScTypeArcPosVarPerm}),
+				T.Ref("_x"),
+			),
+			err: ErrInvalidAlias,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.builder.Build(); err == nil || !strings.HasPrefix(err.Error(), tt.err.Error()) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package sc
+
+import (
+	"fmt"
+)
+
+// Validate checks template before sending. Aliases from params are treated as defined.
+// Returns warnings for suspicious but valid constructions and error for invalid ones
+func (t *ScTemplate) Validate(params map[string]ScAddr) ([]string, error) {
+	var warnings []string
+	defined := make(map[string]interface{})
+	for alias, addr := range params {
+		defined[alias] = addr
+	}
+
+	for i, triple := range t.Triples {
+		items := [3]ScTemplateValue{triple.Source, triple.Edge, triple.Target}
+		for pos, item := range items {
+			switch v := item.Value.(type) {
+			case ScAddr:
+				if !v.IsValid() {
+					return warnings, CommonError(ErrInvalidParameters, fmt.Sprintf("invalid addr in triple %d", i))
+				}
+			case ScType:
+				if pos == 1 && !v.IsEdge() {
+					return warnings, CommonError(ErrInvalidType, fmt.Sprintf("type %d in edge position of triple %d is not an edge type", v.Value, i))
+				}
+				if item.Alias == "" {
+					warnings = append(warnings, fmt.Sprintf("anonymous var element at position %d of triple %d", pos, i))
+				}
+			case string:
+				if _, exists := defined[v]; !exists {
+					return warnings, CommonError(ErrInvalidAlias, fmt.Sprintf("alias %q referenced in triple %d is not defined before", v, i))
+				}
+			default:
+				return warnings, CommonError(ErrInvalidParameters, fmt.Sprintf("unsupported value %v in triple %d", item.Value, i))
+			}
+
+			if item.Alias == "" {
+				continue
+			}
+			if _, isRef := item.Value.(string); isRef {
+				continue
+			}
+			if prev, exists := defined[item.Alias]; exists && prev != item.Value {
+				if _, fromParams := params[item.Alias]; !fromParams {
+					return warnings, CommonError(ErrInvalidAlias, fmt.Sprintf("alias %q is assigned twice with different values in triple %d", item.Alias, i))
+				}
+			}
+			defined[item.Alias] = item.Value
+		}
+	}
+	return warnings, nil
+}
+
+// Normalize returns copy of template where triples with fixed addresses go first.
+// Triples are never moved before triples defining aliases they reference
+func (t *ScTemplate) Normalize() *ScTemplate {
+	normalized, _ := t.normalize(nil)
+	return normalized
+}
+
+// normalize returns reordered template and original index of each triple
+func (t *ScTemplate) normalize(params map[string]ScAddr) (*ScTemplate, []int) {
+	defined := make(map[string]bool)
+	for alias := range params {
+		defined[alias] = true
+	}
+
+	used := make([]bool, len(t.Triples))
+	order := make([]int, 0, len(t.Triples))
+	for len(order) < len(t.Triples) {
+		best, bestAnchors := -1, -1
+		for i, triple := range t.Triples {
+			if used[i] || !triple.refsDefined(defined) {
+				continue
+			}
+			if anchors := triple.anchorsCount(); anchors > bestAnchors {
+				best, bestAnchors = i, anchors
+			}
+		}
+
+		// Template references undefined aliases, keep the rest as is
+		if best < 0 {
+			for i := range t.Triples {
+				if !used[i] {
+					used[i] = true
+					order = append(order, i)
+				}
+			}
+			break
+		}
+
+		used[best] = true
+		order = append(order, best)
+		for _, item := range t.Triples[best].items() {
+			if _, isRef := item.Value.(string); !isRef && item.Alias != "" {
+				defined[item.Alias] = true
+			}
+		}
+	}
+
+	normalized := &ScTemplate{Triples: make([]ScTemplateTriple, len(order))}
+	for i, idx := range order {
+		normalized.Triples[i] = t.Triples[idx]
+	}
+	return normalized, order
+}
+
+func (tr ScTemplateTriple) items() [3]ScTemplateValue {
+	return [3]ScTemplateValue{tr.Source, tr.Edge, tr.Target}
+}
+
+func (tr ScTemplateTriple) anchorsCount() int {
+	count := 0
+	for _, item := range tr.items() {
+		if _, isAddr := item.Value.(ScAddr); isAddr {
+			count++
+		}
+	}
+	return count
+}
+
+func (tr ScTemplateTriple) refsDefined(defined map[string]bool) bool {
+	local := make(map[string]bool)
+	for _, item := range tr.items() {
+		if ref, isRef := item.Value.(string); isRef && !defined[ref] && !local[ref] {
+			return false
+		}
+		if item.Alias != "" {
+			local[item.Alias] = true
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package sc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScTemplateValidate(t *testing.T) {
+	template := &ScTemplate{}
+	template.Triple(ScAddr{Value: 1}, ScType{Value: ScTypeArcPosVarPerm}, []interface{}{ScType{Value: ScTypeNodeVar}, "_x"})
+	template.Triple("_x", ScType{Value: ScTypeArcPosVarPerm}, "_param")
+
+	warnings, err := template.Validate(map[string]ScAddr{"_param": {Value: 2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("expected warnings for 2 anonymous arcs, got %v", warnings)
+	}
+}
+
+func TestScTemplateValidateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		triple [3]interface{}
+		err    error
+	}{
+		{
+			name:   "invalid addr",
+			triple: [3]interface{}{ScAddr{}, ScType{Value: ScTypeArcPosVarPerm}, ScType{Value: ScTypeNodeVar}},
+			err:    ErrInvalidParameters,
+		},
+		{
+			name:   "node type in edge position",
+			triple: [3]interface{}{ScAddr{Value: 1}, ScType{Value: ScTypeNodeVar}, ScType{Value: ScTypeNodeVar}},
+			err:    ErrInvalidType,
+		},
+		{
+			name:   "undefined alias",
+			triple: [3]interface{}{"_missing", ScType{Value: ScTypeArcPosVarPerm}, ScType{Value: ScTypeNodeVar}},
+			err:    ErrInvalidAlias,
+		},
+		{
+			name:   "alias assigned twice",
+			triple: [3]interface{}{[]interface{}{ScAddr{Value: 1}, "_x"}, ScType{Value: ScTypeArcPosVarPerm}, []interface{}{ScAddr{Value: 2}, "_x"}},
+			err:    ErrInvalidAlias,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := &ScTemplate{}
+			template.Triple(tt.triple[0], tt.triple[1], tt.triple[2])
+			if _, err := template.Validate(nil); err == nil || !strings.HasPrefix(err.Error(), tt.err.Error()) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestScTemplateNormalize(t *testing.T) {
+	template := &ScTemplate{}
+	// 0: no anchors, defines _a
+	template.Triple([]interface{}{ScType{Value: ScTypeNodeVar}, "_a"}, ScType{Value: ScTypeArcPosVarPerm}, ScType{Value: ScTypeNodeVar})
+	// 1: two anchors
+	template.Triple(ScAddr{Value: 1}, ScType{Value: ScTypeArcPosVarPerm}, ScAddr{Value: 2})
+	// 2, 3: one anchor each, but reference _a defined by triple 0
+	template.Triple(ScAddr{Value: 3}, ScType{Value: ScTypeArcPosVarPerm}, "_a")
+	template.Triple("_a", ScType{Value: ScTypeArcPosVarPerm}, ScAddr{Value: 4})
+
+	normalized, order := template.normalize(nil)
+
+	expected := []int{1, 0, 2, 3}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected order %v, got %v", expected, order)
+		}
+		if normalized.Triples[i] != template.Triples[expected[i]] {
+			t.Errorf("triple %d is not triple %d of original template", i, expected[i])
+		}
+	}
+}
+
+func TestScTemplateNormalizeUsesParams(t *testing.T) {
+	template := &ScTemplate{}
+	template.Triple([]interface{}{ScType{Value: ScTypeNodeVar}, "_b"}, ScType{Value: ScTypeArcPosVarPerm}, ScType{Value: ScTypeNodeVar})
+	template.Triple(ScAddr{Value: 1}, ScType{Value: ScTypeArcPosVarPerm}, "_param")
+
+	_, order := template.normalize(map[string]ScAddr{"_param": {Value: 2}})
+	if order[0] != 1 || order[1] != 0 {
+		t.Errorf("expected anchored triple with param first, got %v", order)
+	}
+}
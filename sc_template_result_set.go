@@ -0,0 +1,106 @@
+package sc
+
+// ScTemplateResultSet represents template search results in columnar form.
+// Aliases are stored once and addresses of all rows are kept in one flat slice
+type ScTemplateResultSet struct {
+	Aliases map[string]int
+	Width   int
+	Addrs   []ScAddr
+}
+
+// Len returns number of rows in set
+func (s *ScTemplateResultSet) Len() int {
+	if s.Width == 0 {
+		return 0
+	}
+	return len(s.Addrs) / s.Width
+}
+
+// Row returns view of row by index. Row shares memory with set
+func (s *ScTemplateResultSet) Row(i int) ScTemplateResult {
+	return ScTemplateResult{
+		Addrs:   s.Addrs[i*s.Width : (i+1)*s.Width : (i+1)*s.Width],
+		Indices: s.Aliases,
+	}
+}
+
+// Rows returns views of all rows
+func (s *ScTemplateResultSet) Rows() []ScTemplateResult {
+	rows := make([]ScTemplateResult, s.Len())
+	for i := range rows {
+		rows[i] = s.Row(i)
+	}
+	return rows
+}
+
+// ForEach executes function for each row until it returns false
+func (s *ScTemplateResultSet) ForEach(f func(row ScTemplateResult) bool) {
+	for i := 0; i < s.Len(); i++ {
+		if !f(s.Row(i)) {
+			return
+		}
+	}
+}
+
+// Column returns addresses of alias in every row
+func (s *ScTemplateResultSet) Column(alias string) []ScAddr {
+	idx, exists := s.Aliases[alias]
+	if !exists {
+		return nil
+	}
+
+	column := make([]ScAddr, s.Len())
+	for i := range column {
+		column[i] = s.Addrs[i*s.Width+idx]
+	}
+	return column
+}
+
+// Distinct returns unique addresses of alias in order of first appearance
+func (s *ScTemplateResultSet) Distinct(alias string) []ScAddr {
+	idx, exists := s.Aliases[alias]
+	if !exists {
+		return nil
+	}
+
+	seen := make(map[int64]bool)
+	var distinct []ScAddr
+	for i := 0; i < s.Len(); i++ {
+		addr := s.Addrs[i*s.Width+idx]
+		if !seen[addr.Value] {
+			seen[addr.Value] = true
+			distinct = append(distinct, addr)
+		}
+	}
+	return distinct
+}
+
+// restoreTripleOrder maps rows of normalized template back to original triples order
+func (s *ScTemplateResultSet) restoreTripleOrder(order []int) {
+	if order == nil || s.Width != len(order)*3 {
+		return
+	}
+
+	positions := make([]int, s.Width)
+	for i, idx := range order {
+		for k := 0; k < 3; k++ {
+			positions[i*3+k] = idx*3 + k
+		}
+	}
+
+	row := make([]ScAddr, s.Width)
+	for start := 0; start < len(s.Addrs); start += s.Width {
+		for i, addr := range s.Addrs[start : start+s.Width] {
+			row[positions[i]] = addr
+		}
+		copy(s.Addrs[start:start+s.Width], row)
+	}
+
+	aliases := make(map[string]int, len(s.Aliases))
+	for alias, index := range s.Aliases {
+		if index >= 0 && index < len(positions) {
+			aliases[alias] = positions[index]
+		}
+	}
+	s.Aliases = aliases
+}
@@ -0,0 +1,93 @@
+package sc
+
+import "testing"
+
+func testAddrs(values ...int64) []ScAddr {
+	result := make([]ScAddr, len(values))
+	for i, value := range values {
+		result[i] = ScAddr{Value: value}
+	}
+	return result
+}
+
+func TestScTemplateResultSet(t *testing.T) {
+	set := &ScTemplateResultSet{
+		Aliases: map[string]int{"_src": 0, "_trg": 2},
+		Width:   3,
+		Addrs:   testAddrs(1, 10, 2, 1, 11, 3, 4, 12, 2),
+	}
+
+	if set.Len() != 3 {
+		t.Fatalf("expected 3 rows, got %d", set.Len())
+	}
+	if row := set.Row(1); row.Get("_trg").Value != 3 || row.Get(1).Value != 11 {
+		t.Errorf("unexpected row %v", row.Addrs)
+	}
+
+	column := set.Column("_src")
+	if len(column) != 3 || column[0].Value != 1 || column[2].Value != 4 {
+		t.Errorf("unexpected column %v", column)
+	}
+	if set.Column("_missing") != nil {
+		t.Error("expected nil column for unknown alias")
+	}
+
+	distinct := set.Distinct("_trg")
+	if len(distinct) != 2 || distinct[0].Value != 2 || distinct[1].Value != 3 {
+		t.Errorf("unexpected distinct addrs %v", distinct)
+	}
+
+	visited := 0
+	set.ForEach(func(row ScTemplateResult) bool {
+		visited++
+		return visited < 2
+	})
+	if visited != 2 {
+		t.Errorf("expected iteration to stop after 2 rows, visited %d", visited)
+	}
+}
+
+func TestScTemplateResultSetRowIsolation(t *testing.T) {
+	set := &ScTemplateResultSet{Width: 1, Addrs: testAddrs(1, 2)}
+	row := set.Row(0)
+	row.Addrs = append(row.Addrs, ScAddr{Value: 100})
+	if set.Addrs[1].Value != 2 {
+		t.Error("append to row overwrote next row")
+	}
+}
+
+func TestScTemplateResultSetEmpty(t *testing.T) {
+	set := &ScTemplateResultSet{}
+	if set.Len() != 0 || len(set.Rows()) != 0 {
+		t.Error("expected empty set")
+	}
+}
+
+func TestScTemplateResultSetRestoreTripleOrder(t *testing.T) {
+	template := &ScTemplate{}
+	template.Triple([]interface{}{ScType{Value: ScTypeNodeVar}, "_a"}, ScType{Value: ScTypeArcPosVarPerm}, ScType{Value: ScTypeNodeVar})
+	template.Triple(ScAddr{Value: 1}, ScType{Value: ScTypeArcPosVarPerm}, []interface{}{ScType{Value: ScTypeNodeVar}, "_b"})
+	_, order := template.normalize(nil)
+	if order[0] != 1 {
+		t.Fatalf("expected anchored triple first, got %v", order)
+	}
+
+	// Rows as returned for normalized template: triple 1 first, then triple 0
+	set := &ScTemplateResultSet{
+		Aliases: map[string]int{"_b": 2, "_a": 3},
+		Width:   6,
+		Addrs:   testAddrs(1, 20, 2, 3, 30, 4),
+	}
+	set.restoreTripleOrder(order)
+
+	expected := testAddrs(3, 30, 4, 1, 20, 2)
+	for i := range expected {
+		if set.Addrs[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, set.Addrs)
+		}
+	}
+	row := set.Row(0)
+	if row.Get("_a").Value != 3 || row.Get("_b").Value != 2 {
+		t.Errorf("aliases are not remapped: %v", set.Aliases)
+	}
+}
@@ -0,0 +1,202 @@
+package sc
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var scAddrType = reflect.TypeOf(ScAddr{})
+
+// scanField represents struct field bound to template alias
+type scanField struct {
+	index   int
+	alias   string
+	content bool
+}
+
+// scanFields returns fields of struct type tagged with `sc:"alias"`.
+// Fields of string, int and float kinds are bound to link contents
+func scanFields(t reflect.Type) ([]scanField, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, CommonError(ErrInvalidParameters, fmt.Sprintf("%s is not a struct", t))
+	}
+
+	var fields []scanField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		alias, tagged := field.Tag.Lookup("sc")
+		if !tagged || alias == "" || alias == "-" || !field.IsExported() {
+			continue
+		}
+
+		switch {
+		case field.Type == scAddrType:
+			fields = append(fields, scanField{index: i, alias: alias})
+		case isContentKind(field.Type.Kind()):
+			fields = append(fields, scanField{index: i, alias: alias, content: true})
+		default:
+			return nil, CommonError(ErrInvalidType, fmt.Sprintf("field %s has unsupported type %s", field.Name, field.Type))
+		}
+	}
+	return fields, nil
+}
+
+func isContentKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func structValue(dst interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, CommonError(ErrInvalidParameters, "destination should be non-nil pointer to struct")
+	}
+	return v.Elem(), nil
+}
+
+// Scan copies addresses of result into ScAddr fields of dst tagged with `sc:"alias"`.
+// Fields bound to link contents are left untouched, use TemplateSearchInto to fill them
+func (r ScTemplateResult) Scan(dst interface{}) error {
+	v, err := structValue(dst)
+	if err != nil {
+		return err
+	}
+
+	fields, err := scanFields(v.Type())
+	if err != nil {
+		return err
+	}
+	return r.scanAddrs(v, fields)
+}
+
+// scanAddrs copies addresses of result into ScAddr fields of struct value
+func (r ScTemplateResult) scanAddrs(v reflect.Value, fields []scanField) error {
+	for _, field := range fields {
+		if field.content {
+			continue
+		}
+
+		addr, err := r.lookup(field.alias)
+		if err != nil {
+			return err
+		}
+		v.Field(field.index).Set(reflect.ValueOf(addr))
+	}
+	return nil
+}
+
+func (r ScTemplateResult) lookup(alias string) (ScAddr, error) {
+	idx, exists := r.Indices[alias]
+	if !exists || idx < 0 || idx >= len(r.Addrs) {
+		return ScAddr{}, CommonError(ErrInvalidAlias, fmt.Sprintf("alias %q is not found in result", alias))
+	}
+	return r.Addrs[idx], nil
+}
+
+// TemplateSearchInto searches by template and maps every result into T using `sc:"alias"` tags.
+// Contents of links bound to string, int and float fields are fetched with one batched request
+func TemplateSearchInto[T any](c *ScClient, template *ScTemplate) ([]T, error) {
+	results, err := c.TemplateSearch(template)
+	if err != nil {
+		return nil, err
+	}
+	return scanResults[T](c, results)
+}
+
+// scanResults maps results into T fetching contents of all bound links with one request
+func scanResults[T any](c *ScClient, results []ScTemplateResult) ([]T, error) {
+	fields, err := scanFields(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]T, len(results))
+	var links []ScAddr
+	for i, result := range results {
+		if err := result.scanAddrs(reflect.ValueOf(&items[i]).Elem(), fields); err != nil {
+			return nil, err
+		}
+
+		for _, field := range fields {
+			if !field.content {
+				continue
+			}
+			addr, err := result.lookup(field.alias)
+			if err != nil {
+				return nil, err
+			}
+			links = append(links, addr)
+		}
+	}
+
+	if len(links) == 0 {
+		return items, nil
+	}
+
+	contents, err := c.getLinkContents(links)
+	if err != nil {
+		return nil, err
+	}
+
+	next := 0
+	for i := range items {
+		v := reflect.ValueOf(&items[i]).Elem()
+		for _, field := range fields {
+			if !field.content {
+				continue
+			}
+			if next < len(contents) && contents[next] != nil {
+				if err := setContent(v.Field(field.index), contents[next].Data); err != nil {
+					return nil, err
+				}
+			}
+			next++
+		}
+	}
+	return items, nil
+}
+
+// setContent converts link content into field value
+func setContent(field reflect.Value, data interface{}) error {
+	switch field.Kind() {
+	case reflect.String:
+		if s, ok := data.(string); ok {
+			field.SetString(s)
+		} else {
+			field.SetString(fmt.Sprint(data))
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch d := data.(type) {
+		case float64:
+			field.SetInt(int64(d))
+		case string:
+			n, err := strconv.ParseInt(d, 10, 64)
+			if err != nil {
+				return InvalidValueError(fmt.Sprintf("can't convert %q to int", d))
+			}
+			field.SetInt(n)
+		default:
+			return InvalidValueError(fmt.Sprintf("can't convert %v to int", data))
+		}
+	case reflect.Float32, reflect.Float64:
+		switch d := data.(type) {
+		case float64:
+			field.SetFloat(d)
+		case string:
+			f, err := strconv.ParseFloat(d, 64)
+			if err != nil {
+				return InvalidValueError(fmt.Sprintf("can't convert %q to float", d))
+			}
+			field.SetFloat(f)
+		default:
+			return InvalidValueError(fmt.Sprintf("can't convert %v to float", data))
+		}
+	}
+	return nil
+}
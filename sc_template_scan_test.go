@@ -0,0 +1,99 @@
+package sc_test
+
+import (
+	"strings"
+	"testing"
+
+	sc "github.com/temapriemnik/go-sc-client"
+	"github.com/temapriemnik/go-sc-client/agenttest"
+)
+
+type scannedCity struct {
+	City       sc.ScAddr `sc:"_city"`
+	Name       string    `sc:"_name"`
+	Population int       `sc:"_population"`
+	Area       float64   `sc:"_area"`
+	Skipped    sc.ScAddr `sc:"-"`
+	Untagged   sc.ScAddr
+}
+
+func TestScTemplateResultScan(t *testing.T) {
+	result := sc.ScTemplateResult{
+		Addrs:   []sc.ScAddr{{Value: 1}, {Value: 2}, {Value: 3}},
+		Indices: map[string]int{"_city": 0, "_name": 2},
+	}
+
+	var city struct {
+		City sc.ScAddr `sc:"_city"`
+		Name string    `sc:"_name"`
+	}
+	city.Name = "kept"
+	if err := result.Scan(&city); err != nil {
+		t.Fatal(err)
+	}
+	if city.City.Value != 1 || city.Name != "kept" {
+		t.Errorf("unexpected scanned struct %+v", city)
+	}
+
+	var missing struct {
+		Country sc.ScAddr `sc:"_country"`
+	}
+	if err := result.Scan(&missing); err == nil || !strings.HasPrefix(err.Error(), sc.ErrInvalidAlias.Error()) {
+		t.Errorf("expected invalid alias error, got %v", err)
+	}
+	var unsupported struct {
+		Flag bool `sc:"_city"`
+	}
+	if err := result.Scan(&unsupported); err == nil || !strings.HasPrefix(err.Error(), sc.ErrInvalidType.Error()) {
+		t.Errorf("expected invalid type error, got %v", err)
+	}
+	if err := result.Scan(city); err == nil || !strings.HasPrefix(err.Error(), sc.ErrInvalidParameters.Error()) {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+}
+
+func TestTemplateSearchInto(t *testing.T) {
+	kit := agenttest.New(t)
+	addrs := kit.LoadSCs(`
+		concept_city -> ..minsk; ..paris;;
+		..minsk => nrel_name: [Minsk];;
+		..minsk => nrel_population: [2000000];;
+		..minsk => nrel_area: [409.5];;
+		..paris => nrel_name: [Paris];;
+		..paris => nrel_population: [2100000];;
+		..paris => nrel_area: [105.4];;
+	`)
+
+	template := &sc.ScTemplate{}
+	template.Triple(addrs["concept_city"], sc.ScType{Value: sc.ScTypeArcPosVarPerm}, []interface{}{sc.ScType{Value: sc.ScTypeNodeVar}, "_city"})
+	for alias, relation := range map[string]string{"_name": "nrel_name", "_population": "nrel_population", "_area": "nrel_area"} {
+		template.TripleWithRelation("_city", sc.ScType{Value: sc.ScTypeDEdgeVar}, []interface{}{sc.ScType{Value: sc.ScTypeLinkVar}, alias},
+			sc.ScType{Value: sc.ScTypeArcPosVarPerm}, addrs[relation])
+	}
+
+	cities, err := sc.TemplateSearchInto[scannedCity](kit.Client, template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cities) != 2 {
+		t.Fatalf("expected 2 cities, got %+v", cities)
+	}
+	byName := map[string]scannedCity{}
+	for _, city := range cities {
+		byName[city.Name] = city
+	}
+	minsk, paris := byName["Minsk"], byName["Paris"]
+	if !minsk.City.Equal(addrs["..minsk"]) || minsk.Population != 2000000 || minsk.Area != 409.5 {
+		t.Errorf("unexpected Minsk %+v", minsk)
+	}
+	if !paris.City.Equal(addrs["..paris"]) || paris.Population != 2100000 || paris.Area != 105.4 {
+		t.Errorf("unexpected Paris %+v", paris)
+	}
+	if minsk.Skipped.IsValid() || minsk.Untagged.IsValid() {
+		t.Errorf("untagged fields are filled: %+v", minsk)
+	}
+
+	if _, err := sc.TemplateSearchInto[int](kit.Client, template); err == nil || !strings.HasPrefix(err.Error(), sc.ErrInvalidParameters.Error()) {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package sc
+
+import "testing"
+
+func TestTripleWithRelationKeepsEdgeValue(t *testing.T) {
+	src := ScAddr{Value: 1}
+	rel := ScAddr{Value: 2}
+
+	template := &ScTemplate{}
+	template.TripleWithRelation(
+		src,
+		[]interface{}{ScType{Value: ScTypeDEdgeCommon | ScTypeVar}, "_edge"},
+		[]interface{}{ScType{Value: ScTypeNode | ScTypeVar}, "_trg"},
+		ScType{Value: ScTypeArcPosVarPerm},
+		rel,
+	)
+
+	if len(template.Triples) != 2 {
+		t.Fatalf("expected 2 triples, got %d", len(template.Triples))
+	}
+	edge := template.Triples[0].Edge
+	if edge.Alias != "_edge" {
+		t.Errorf("expected edge alias _edge, got %q", edge.Alias)
+	}
+	if edgeType, ok := edge.Value.(ScType); !ok || edgeType.Value != ScTypeDEdgeCommon|ScTypeVar {
+		t.Errorf("expected edge value ScType %d, got %#v", ScTypeDEdgeCommon|ScTypeVar, edge.Value)
+	}
+	if ref, ok := template.Triples[1].Target.Value.(string); !ok || ref != "_edge" {
+		t.Errorf("expected relation arc to target _edge, got %#v", template.Triples[1].Target.Value)
+	}
+
+	payload, err := (&ScClient{}).prepareTemplatePayload(template)
+	if err != nil {
+		t.Fatalf("failed to prepare payload: %v", err)
+	}
+	item := payload[0].([]interface{})[1].(map[string]interface{})
+	if item["type"] != "type" || item["value"] != ScTypeDEdgeCommon|ScTypeVar || item["alias"] != "_edge" {
+		t.Errorf("unexpected edge item %v", item)
+	}
+}
+
+func TestTripleWithRelationGeneratesEdgeAlias(t *testing.T) {
+	template := &ScTemplate{}
+	template.TripleWithRelation(
+		ScAddr{Value: 1},
+		ScType{Value: ScTypeDEdgeCommon | ScTypeVar},
+		ScType{Value: ScTypeNode | ScTypeVar},
+		ScType{Value: ScTypeArcPosVarPerm},
+		ScAddr{Value: 2},
+	)
+
+	edge := template.Triples[0].Edge
+	if edge.Alias == "" {
+		t.Fatal("expected generated edge alias")
+	}
+	if _, ok := edge.Value.(ScType); !ok {
+		t.Errorf("expected edge value ScType, got %#v", edge.Value)
+	}
+	if ref := template.Triples[1].Target.Value; ref != edge.Alias {
+		t.Errorf("expected relation arc to target %q, got %#v", edge.Alias, ref)
+	}
+}
@@ -0,0 +1,386 @@
+package sc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// ScTemplateChangeKind represents kind of live result set change
+type ScTemplateChangeKind int
+
+const (
+	ScTemplateRowAdded ScTemplateChangeKind = iota
+	ScTemplateRowRemoved
+)
+
+// ScTemplateChange represents row added to or removed from live result set
+type ScTemplateChange struct {
+	Kind ScTemplateChangeKind
+	Row  ScTemplateResult
+}
+
+// TemplateWatch keeps results of template up to date with knowledge base
+type TemplateWatch struct {
+	client    *ScClient
+	template  *ScTemplate
+	params    map[string]ScAddr
+	changes   chan ScTemplateChange
+	listeners []*ScEventListener
+
+	mu      sync.Mutex
+	rows    map[string]ScTemplateResult
+	pending []watchEvent
+	wake    chan struct{}
+	ready   chan struct{}
+	done    chan struct{}
+	closed  bool
+	err     error
+}
+
+// watchEvent represents event received on template anchor
+type watchEvent struct {
+	anchor    ScAddr
+	eventType ScEventType
+	edge      ScAddr
+}
+
+var watchEventTypes = []ScEventType{
+	ScEventAddOutgoingEdge,
+	ScEventAddIngoingEdge,
+	ScEventRemoveOutgoingEdge,
+	ScEventRemoveIngoingEdge,
+	ScEventRemoveElement,
+}
+
+// WatchTemplate searches by template and then emits rows added to and removed from results
+// as knowledge base changes. Events are listened on every constant address of template and params.
+// Watch is stopped when ctx is cancelled or Close is called
+func (c *ScClient) WatchTemplate(ctx context.Context, template *ScTemplate, params map[string]ScAddr) (*TemplateWatch, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	w := &TemplateWatch{
+		client:   c,
+		template: template,
+		params:   params,
+		changes:  make(chan ScTemplateChange, DefaultSubscriptionBuffer),
+		rows:     make(map[string]ScTemplateResult),
+		wake:     make(chan struct{}, 1),
+		ready:    make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	// Run is started before anything may fail, so changes channel is closed on every path
+	go w.run(ctx)
+
+	// Listen before search, so changes made in between are not lost
+	var listened []listenerParams
+	for _, anchor := range w.anchors() {
+		for _, eventType := range watchEventTypes {
+			anchor, eventType := anchor, eventType
+			listened = append(listened, listenerParams{
+				addr:      anchor,
+				eventType: eventType,
+				callback: func(elAddr, edge, other ScAddr, eventID int) {
+					w.enqueue(watchEvent{anchor: anchor, eventType: eventType, edge: edge})
+				},
+			})
+		}
+	}
+	listeners, err := c.addListeners(listened)
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	w.listeners = listeners
+
+	results, err := c.TemplateSearchWithParams(template, params)
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	for _, row := range results {
+		w.rows[rowKey(row)] = row
+	}
+
+	close(w.ready)
+	return w, nil
+}
+
+// anchors returns unique constant addresses of template and params
+func (w *TemplateWatch) anchors() []ScAddr {
+	seen := make(map[int64]bool)
+	var anchors []ScAddr
+	add := func(addr ScAddr) {
+		if addr.IsValid() && !seen[addr.Value] {
+			seen[addr.Value] = true
+			anchors = append(anchors, addr)
+		}
+	}
+
+	for _, triple := range w.template.Triples {
+		for _, item := range triple.items() {
+			if addr, isAddr := item.Value.(ScAddr); isAddr {
+				add(addr)
+			}
+		}
+	}
+	for _, addr := range w.params {
+		add(addr)
+	}
+	return anchors
+}
+
+func (w *TemplateWatch) enqueue(event watchEvent) {
+	w.mu.Lock()
+	if !w.closed {
+		w.pending = append(w.pending, event)
+	}
+	w.mu.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *TemplateWatch) run(ctx context.Context) {
+	defer close(w.changes)
+
+	// Events received before initial search are applied after it.
+	// Listeners are still being added, so cancellation is handled after that too
+	select {
+	case <-w.done:
+		return
+	case <-w.ready:
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.stop(ctx.Err())
+			return
+		case <-w.done:
+			return
+		case <-w.wake:
+		}
+
+		w.mu.Lock()
+		events := w.pending
+		w.pending = nil
+		w.mu.Unlock()
+
+		changes, err := w.apply(events)
+		if err != nil {
+			log.Printf("Failed to update template watch: %v", err)
+		}
+		for i, change := range changes {
+			select {
+			case w.changes <- change:
+			case <-ctx.Done():
+				w.stop(CommonError(ErrInvalidState, fmt.Sprintf("%d changes are dropped: %v", len(changes)-i, ctx.Err())))
+				return
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// stop closes watch stopped by context and keeps the reason
+func (w *TemplateWatch) stop(reason error) {
+	w.mu.Lock()
+	if !w.closed {
+		w.err = reason
+	}
+	w.mu.Unlock()
+
+	if err := w.Close(); err != nil {
+		log.Printf("Failed to close template watch: %v", err)
+	}
+}
+
+// apply updates rows by received events. Removed edges and elements drop rows containing them,
+// added edges are checked by searching only rows with the new edge when its triple has alias
+func (w *TemplateWatch) apply(events []watchEvent) ([]ScTemplateChange, error) {
+	var changes []ScTemplateChange
+	var searches []map[string]ScAddr
+	fullSearch := false
+
+	for _, event := range events {
+		switch event.eventType {
+		case ScEventRemoveOutgoingEdge, ScEventRemoveIngoingEdge:
+			changes = append(changes, w.removeRowsWith(event.edge)...)
+		case ScEventRemoveElement:
+			changes = append(changes, w.removeRowsWith(event.anchor)...)
+		default:
+			params, ok := w.edgeSearches(event)
+			if !ok {
+				fullSearch = true
+			}
+			searches = append(searches, params...)
+		}
+	}
+
+	if fullSearch {
+		results, err := w.client.TemplateSearchWithParams(w.template, w.params)
+		if err != nil {
+			return changes, err
+		}
+		return append(changes, w.replaceRows(results)...), nil
+	}
+
+	for _, params := range searches {
+		results, err := w.client.TemplateSearchWithParams(w.template, params)
+		if err != nil {
+			return changes, err
+		}
+		changes = append(changes, w.addRows(results)...)
+	}
+	return changes, nil
+}
+
+// edgeSearches returns params binding added edge to every triple it can belong to.
+// Returns false if one of such triples has no edge alias
+func (w *TemplateWatch) edgeSearches(event watchEvent) ([]map[string]ScAddr, bool) {
+	outgoing := event.eventType == ScEventAddOutgoingEdge
+	var searches []map[string]ScAddr
+
+	for _, triple := range w.template.Triples {
+		end := triple.Target
+		if outgoing {
+			end = triple.Source
+		}
+		if !w.isAnchor(end, event.anchor) {
+			continue
+		}
+
+		if _, isType := triple.Edge.Value.(ScType); !isType || triple.Edge.Alias == "" {
+			return nil, false
+		}
+
+		params := make(map[string]ScAddr, len(w.params)+1)
+		for alias, addr := range w.params {
+			params[alias] = addr
+		}
+		params[triple.Edge.Alias] = event.edge
+		searches = append(searches, params)
+	}
+	return searches, true
+}
+
+func (w *TemplateWatch) isAnchor(item ScTemplateValue, anchor ScAddr) bool {
+	switch v := item.Value.(type) {
+	case ScAddr:
+		return v.Equal(anchor)
+	case string:
+		return w.params[v].Equal(anchor)
+	default:
+		return item.Alias != "" && w.params[item.Alias].Equal(anchor)
+	}
+}
+
+func (w *TemplateWatch) removeRowsWith(addr ScAddr) []ScTemplateChange {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var changes []ScTemplateChange
+	for key, row := range w.rows {
+		for _, a := range row.Addrs {
+			if a.Equal(addr) {
+				delete(w.rows, key)
+				changes = append(changes, ScTemplateChange{Kind: ScTemplateRowRemoved, Row: row})
+				break
+			}
+		}
+	}
+	return changes
+}
+
+func (w *TemplateWatch) addRows(results []ScTemplateResult) []ScTemplateChange {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var changes []ScTemplateChange
+	for _, row := range results {
+		key := rowKey(row)
+		if _, exists := w.rows[key]; !exists {
+			w.rows[key] = row
+			changes = append(changes, ScTemplateChange{Kind: ScTemplateRowAdded, Row: row})
+		}
+	}
+	return changes
+}
+
+func (w *TemplateWatch) replaceRows(results []ScTemplateResult) []ScTemplateChange {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	rows := make(map[string]ScTemplateResult, len(results))
+	var changes []ScTemplateChange
+	for _, row := range results {
+		key := rowKey(row)
+		rows[key] = row
+		if _, exists := w.rows[key]; !exists {
+			changes = append(changes, ScTemplateChange{Kind: ScTemplateRowAdded, Row: row})
+		}
+	}
+	for key, row := range w.rows {
+		if _, exists := rows[key]; !exists {
+			changes = append(changes, ScTemplateChange{Kind: ScTemplateRowRemoved, Row: row})
+		}
+	}
+	w.rows = rows
+	return changes
+}
+
+// rowKey returns key identifying row by its addresses
+func rowKey(row ScTemplateResult) string {
+	var b strings.Builder
+	for _, addr := range row.Addrs {
+		fmt.Fprintf(&b, "%d,", addr.Value)
+	}
+	return b.String()
+}
+
+// Changes returns channel of result changes. Channel is closed when watch is stopped
+func (w *TemplateWatch) Changes() <-chan ScTemplateChange {
+	return w.changes
+}
+
+// Err returns reason watch was stopped by context: context error or error listing number of
+// changes dropped because of cancellation. Returns nil while watch runs or if it is closed by Close
+func (w *TemplateWatch) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Rows returns current results
+func (w *TemplateWatch) Rows() []ScTemplateResult {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	rows := make([]ScTemplateResult, 0, len(w.rows))
+	for _, row := range w.rows {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Close removes event listeners with one request and stops watch
+func (w *TemplateWatch) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	w.mu.Unlock()
+
+	return w.client.closeListeners(w.listeners)
+}
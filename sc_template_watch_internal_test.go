@@ -0,0 +1,49 @@
+package sc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWatchTemplateCreatesEventsWithOneRequest(t *testing.T) {
+	var requests, events atomic.Int64
+	server := newTestServer(t, func(request Request) Response {
+		switch request.Type {
+		case "events":
+			requests.Add(1)
+			payload, _ := request.Payload.(map[string]interface{})
+			items, _ := payload["create"].([]interface{})
+			ids := make([]interface{}, len(items))
+			for i := range ids {
+				ids[i] = events.Add(1)
+			}
+			return Response{Status: true, Payload: ids}
+		case "search_template":
+			return Response{Status: true, Payload: map[string]interface{}{"aliases": map[string]interface{}{}, "addrs": []interface{}{}}}
+		}
+		return Response{Status: false}
+	})
+	client := NewScClient(testServerURL(server))
+	defer client.Close()
+
+	template := &ScTemplate{}
+	template.Triple(ScAddr{Value: 1}, []interface{}{ScType{Value: ScTypeArcPosVarPerm}, "_arc"}, ScType{Value: ScTypeNodeVar})
+	template.Triple(ScAddr{Value: 2}, ScType{Value: ScTypeArcPosVarPerm}, ScType{Value: ScTypeNodeVar})
+
+	watch, err := client.WatchTemplate(context.Background(), template, map[string]ScAddr{"_el": {Value: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 1 || events.Load() != int64(3*len(watchEventTypes)) {
+		t.Errorf("%d events are created with %d requests", events.Load(), requests.Load())
+	}
+
+	requests.Store(0)
+	if err := watch.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("events are destroyed with %d requests", requests.Load())
+	}
+}
@@ -0,0 +1,106 @@
+package sc_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	sc "github.com/temapriemnik/go-sc-client"
+	"github.com/temapriemnik/go-sc-client/agenttest"
+)
+
+func TestWatchTemplate(t *testing.T) {
+	kit := agenttest.New(t)
+	addrs := kit.LoadSCs(`watched_set -> ..first;;`)
+	set := addrs["watched_set"]
+
+	template := &sc.ScTemplate{}
+	template.Triple(set, []interface{}{sc.ScType{Value: sc.ScTypeArcPosVarPerm}, "_arc"}, []interface{}{sc.ScType{Value: sc.ScTypeNodeVar}, "_el"})
+
+	watch, err := kit.Client.WatchTemplate(context.Background(), template, nil)
+	if err != nil {
+		t.Fatalf("failed to watch template: %v", err)
+	}
+	if rows := watch.Rows(); len(rows) != 1 {
+		t.Fatalf("expected 1 initial row, got %d", len(rows))
+	}
+
+	second := kit.LoadSCs(`watched_set -> ..second;;`)["..second"]
+	select {
+	case change := <-watch.Changes():
+		if change.Kind != sc.ScTemplateRowAdded || !change.Row.Get("_el").Equal(second) {
+			t.Errorf("unexpected change %+v", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("added row is not received")
+	}
+
+	if err := watch.Close(); err != nil {
+		t.Fatalf("failed to close watch: %v", err)
+	}
+	select {
+	case _, open := <-watch.Changes():
+		if open {
+			t.Error("unexpected change after close")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("changes channel is not closed")
+	}
+}
+
+func TestWatchTemplateClosedByContext(t *testing.T) {
+	kit := agenttest.New(t)
+	set := kit.Keynode("watched_set", sc.ScTypeNodeConst)
+
+	template := &sc.ScTemplate{}
+	template.Triple(set, []interface{}{sc.ScType{Value: sc.ScTypeArcPosVarPerm}, "_arc"}, sc.ScType{Value: sc.ScTypeNodeVar})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watch, err := kit.Client.WatchTemplate(ctx, template, nil)
+	if err != nil {
+		t.Fatalf("failed to watch template: %v", err)
+	}
+	cancel()
+
+	select {
+	case <-watch.Changes():
+	case <-time.After(5 * time.Second):
+		t.Fatal("changes channel is not closed")
+	}
+	if count := kit.Client.ListenersCount(set, sc.ScEventAddOutgoingEdge); count != 0 {
+		t.Errorf("expected listeners to be closed, got %d", count)
+	}
+}
+
+func TestWatchTemplateReportsChangesDroppedByContext(t *testing.T) {
+	kit := agenttest.New(t)
+	set := kit.Keynode("watched_set", sc.ScTypeNodeConst)
+
+	template := &sc.ScTemplate{}
+	template.Triple(set, []interface{}{sc.ScType{Value: sc.ScTypeArcPosVarPerm}, "_arc"}, sc.ScType{Value: sc.ScTypeNodeVar})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watch, err := kit.Client.WatchTemplate(ctx, template, nil)
+	if err != nil {
+		t.Fatalf("failed to watch template: %v", err)
+	}
+
+	// Nobody reads changes, so watch blocks sending them after buffer is full
+	addMembers(t, kit, set, sc.DefaultSubscriptionBuffer+10)
+	waitFor(t, func() bool { return len(watch.Changes()) == sc.DefaultSubscriptionBuffer })
+	cancel()
+	waitFor(t, func() bool { return watch.Err() != nil })
+
+	received := 0
+	for range watch.Changes() {
+		received++
+	}
+	if received != sc.DefaultSubscriptionBuffer {
+		t.Errorf("received %d changes", received)
+	}
+	if !strings.Contains(watch.Err().Error(), "changes are dropped") {
+		t.Errorf("unexpected error %v", watch.Err())
+	}
+}
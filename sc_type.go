@@ -1,5 +1,10 @@
 package sc
 
+import (
+	"fmt"
+	"strings"
+)
+
 // ScType represents SC type
 type ScType struct {
 	Value int
@@ -39,3 +44,86 @@ func (t ScType) IsValid() bool {
 func (t ScType) Equal(other ScType) bool {
 	return t.Value == other.Value
 }
+
+// AsConst returns constant version of type
+func (t ScType) AsConst() ScType {
+	return ScType{Value: (t.Value &^ ScTypeVar) | ScTypeConst}
+}
+
+// AsVar returns variable version of type
+func (t ScType) AsVar() ScType {
+	return ScType{Value: (t.Value &^ ScTypeConst) | ScTypeVar}
+}
+
+// String returns type keynodes of type joined with "|"
+func (t ScType) String() string {
+	if !t.IsValid() {
+		return "sc_unknown"
+	}
+
+	var parts []string
+	switch {
+	case t.IsNode():
+		parts = append(parts, nodeTypeKeynode(t))
+	case t.IsLink():
+		parts = append(parts, "sc_link")
+	case (t.Value & ScTypeUEdgeCommon) != 0:
+		parts = append(parts, "sc_edge_ucommon")
+	case (t.Value & ScTypeDEdgeCommon) != 0:
+		parts = append(parts, "sc_edge_dcommon")
+	case (t.Value & ScTypeEdgeAccess) != 0:
+		parts = append(parts, "sc_edge_access")
+	}
+
+	if t.IsConst() {
+		parts = append(parts, "sc_const")
+	}
+	if t.IsVar() {
+		parts = append(parts, "sc_var")
+	}
+
+	if t.IsEdge() {
+		flags := []struct {
+			mask int
+			name string
+		}{
+			{ScTypeEdgePos, "sc_edge_pos"},
+			{ScTypeEdgeNeg, "sc_edge_neg"},
+			{ScTypeEdgeFuz, "sc_edge_fuz"},
+			{ScTypeEdgeTemp, "sc_edge_temp"},
+			{ScTypeEdgePerm, "sc_edge_perm"},
+		}
+		for _, flag := range flags {
+			if (t.Value & flag.mask) != 0 {
+				parts = append(parts, flag.name)
+			}
+		}
+	}
+
+	if len(parts) == 0 {
+		return fmt.Sprintf("sc_type(%d)", t.Value)
+	}
+	return strings.Join(parts, "|")
+}
+
+// nodeTypeKeynode returns keynode of node subtype
+func nodeTypeKeynode(t ScType) string {
+	subtypes := []struct {
+		mask int
+		name string
+	}{
+		{ScTypeNodeTuple, "sc_node_tuple"},
+		{ScTypeNodeStruct, "sc_node_struct"},
+		{ScTypeNodeRole, "sc_node_role_relation"},
+		{ScTypeNodeNoRole, "sc_node_norole_relation"},
+		{ScTypeNodeClass, "sc_node_class"},
+		{ScTypeNodeAbstract, "sc_node_abstract"},
+		{ScTypeNodeMaterial, "sc_node_material"},
+	}
+	for _, subtype := range subtypes {
+		if (t.Value & subtype.mask) != 0 {
+			return subtype.name
+		}
+	}
+	return "sc_node"
+}
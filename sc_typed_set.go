@@ -0,0 +1,238 @@
+package sc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ScLoaderFunc resolves set member into Go value
+type ScLoaderFunc[T any] func(c *ScClient, addr ScAddr) (T, error)
+
+// ScBatchLoaderFunc resolves set members into Go values. Values and errors are aligned with addrs
+type ScBatchLoaderFunc[T any] func(c *ScClient, addrs []ScAddr) ([]T, []error)
+
+// ScTypedSet represents SC set which members are materialized into values of T.
+// Values are loaded once when member joins the set and cached until it leaves.
+// Members joining together, like existing members on initialization, are loaded with one loader call
+type ScTypedSet[T any] struct {
+	Set      *ScSet
+	OnAdd    func(addr ScAddr, item T)
+	OnRemove func(addr ScAddr, item T)
+	OnError  func(addr ScAddr, err error)
+
+	loader ScBatchLoaderFunc[T]
+	mu     sync.RWMutex
+	values map[int64]T
+}
+
+// NewScTypedSet creates new typed SC set with loader called for every member.
+// Use NewScTypedSetBatch to load members joining together with fewer requests
+func NewScTypedSet[T any](client *ScClient, addr ScAddr, loader ScLoaderFunc[T], filterType *ScType) (*ScTypedSet[T], error) {
+	if loader == nil {
+		return nil, CommonError(ErrInvalidParameters, "loader should be set")
+	}
+
+	return NewScTypedSetBatch(client, addr, func(c *ScClient, addrs []ScAddr) ([]T, []error) {
+		values := make([]T, len(addrs))
+		errs := make([]error, len(addrs))
+		for i, addr := range addrs {
+			values[i], errs[i] = loader(c, addr)
+		}
+		return values, errs
+	}, filterType)
+}
+
+// NewScTypedSetBatch creates new typed SC set with loader called for members joining together
+func NewScTypedSetBatch[T any](client *ScClient, addr ScAddr, loader ScBatchLoaderFunc[T], filterType *ScType) (*ScTypedSet[T], error) {
+	if loader == nil {
+		return nil, CommonError(ErrInvalidParameters, "loader should be set")
+	}
+
+	typed := &ScTypedSet[T]{
+		loader: loader,
+		values: make(map[int64]T),
+	}
+
+	set, err := NewScSet(client, addr, typed.onInitialize, typed.onAdd, typed.onRemove, filterType)
+	if err != nil {
+		return nil, err
+	}
+	set.addBatch = typed.onAdd
+	set.removeBatch = typed.onRemove
+	typed.Set = set
+	return typed, nil
+}
+
+// TemplateLoader creates loader which searches template built for member
+// and maps the first result into T using `sc:"alias"` tags
+func TemplateLoader[T any](template func(addr ScAddr) *ScTemplate) ScLoaderFunc[T] {
+	return func(c *ScClient, addr ScAddr) (T, error) {
+		var empty T
+		items, err := TemplateSearchInto[T](c, template(addr))
+		if err != nil {
+			return empty, err
+		}
+		if len(items) == 0 {
+			return empty, CommonError(ErrElementNotFound, fmt.Sprintf("template has no results for %v", addr))
+		}
+		return items[0], nil
+	}
+}
+
+// TemplateBatchLoader creates batch loader which searches templates built for members
+// without waiting for each other and maps the first result of each into T using `sc:"alias"` tags.
+// Contents of links of all members are fetched with one request
+func TemplateBatchLoader[T any](template func(addr ScAddr) *ScTemplate) ScBatchLoaderFunc[T] {
+	return func(c *ScClient, addrs []ScAddr) ([]T, []error) {
+		values := make([]T, len(addrs))
+		errs := make([]error, len(addrs))
+		fail := func(err error) ([]T, []error) {
+			for i := range errs {
+				errs[i] = err
+			}
+			return values, errs
+		}
+
+		requests := make([]<-chan *ScTemplateResultSet, len(addrs))
+		for i, addr := range addrs {
+			request, err := c.sendTemplateSearch(template(addr), nil)
+			if err != nil {
+				errs[i] = err
+				continue
+			}
+			requests[i] = request
+		}
+
+		var found []int
+		var rows []ScTemplateResult
+		for i, request := range requests {
+			if request == nil {
+				continue
+			}
+			set, err := waitTemplateSearch(request)
+			if err != nil {
+				return fail(err)
+			}
+			if set.Len() == 0 {
+				errs[i] = CommonError(ErrElementNotFound, fmt.Sprintf("template has no results for %v", addrs[i]))
+				continue
+			}
+			found = append(found, i)
+			rows = append(rows, set.Row(0))
+		}
+
+		items, err := scanResults[T](c, rows)
+		if err != nil {
+			return fail(err)
+		}
+		for k, i := range found {
+			values[i] = items[k]
+		}
+		return values, errs
+	}
+}
+
+// Initialize initializes underlying set and loads values of existing members
+func (s *ScTypedSet[T]) Initialize(ctx context.Context) error {
+	return s.Set.Initialize(ctx)
+}
+
+// Close closes underlying set
+func (s *ScTypedSet[T]) Close() error {
+	return s.Set.Close()
+}
+
+// load loads values of members and calls OnAdd for them.
+// Values of members which left the set while loading are dropped
+func (s *ScTypedSet[T]) load(addrs []ScAddr) {
+	if len(addrs) == 0 {
+		return
+	}
+
+	values, errs := s.loader(s.Set.Client, addrs)
+	for i, addr := range addrs {
+		var err error
+		if i < len(errs) {
+			err = errs[i]
+		}
+		if err == nil && i >= len(values) {
+			err = CommonError(ErrInvalidState, fmt.Sprintf("loader returned no value for %v", addr))
+		}
+		if err != nil {
+			if s.OnError != nil {
+				s.OnError(addr, err)
+			}
+			continue
+		}
+
+		if !s.Set.Contains(addr) {
+			continue
+		}
+		s.mu.Lock()
+		s.values[addr.Value] = values[i]
+		s.mu.Unlock()
+
+		if s.OnAdd != nil {
+			s.OnAdd(addr, values[i])
+		}
+	}
+}
+
+func (s *ScTypedSet[T]) onInitialize(addrs []ScAddr) error {
+	s.load(addrs)
+	return nil
+}
+
+func (s *ScTypedSet[T]) onAdd(addrs []ScAddr) error {
+	s.load(addrs)
+	return nil
+}
+
+func (s *ScTypedSet[T]) onRemove(addrs []ScAddr) error {
+	for _, addr := range addrs {
+		s.mu.Lock()
+		value, exists := s.values[addr.Value]
+		delete(s.values, addr.Value)
+		s.mu.Unlock()
+
+		if exists && s.OnRemove != nil {
+			s.OnRemove(addr, value)
+		}
+	}
+	return nil
+}
+
+// Get returns cached value of member
+func (s *ScTypedSet[T]) Get(addr ScAddr) (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, exists := s.values[addr.Value]
+	return value, exists
+}
+
+// Items returns cached values of all members ordered by their addresses
+func (s *ScTypedSet[T]) Items() []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]int64, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	items := make([]T, len(keys))
+	for i, key := range keys {
+		items[i] = s.values[key]
+	}
+	return items
+}
+
+// Len returns number of loaded members
+func (s *ScTypedSet[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.values)
+}
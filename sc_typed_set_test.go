@@ -0,0 +1,139 @@
+package sc_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sc "github.com/temapriemnik/go-sc-client"
+	"github.com/temapriemnik/go-sc-client/agenttest"
+)
+
+type typedMember struct {
+	Addr sc.ScAddr `sc:"_member"`
+	Name string    `sc:"_name"`
+}
+
+func typedMemberTemplate(nrelName sc.ScAddr) func(sc.ScAddr) *sc.ScTemplate {
+	return func(addr sc.ScAddr) *sc.ScTemplate {
+		template := &sc.ScTemplate{}
+		template.TripleWithRelation(
+			[]interface{}{addr, "_member"},
+			sc.ScType{Value: sc.ScTypeDEdgeVar},
+			[]interface{}{sc.ScType{Value: sc.ScTypeLinkVar}, "_name"},
+			sc.ScType{Value: sc.ScTypeArcPosVarPerm},
+			nrelName,
+		)
+		return template
+	}
+}
+
+func TestScTypedSetLoadsMembersInBatches(t *testing.T) {
+	kit := agenttest.New(t)
+	addrs := kit.LoadSCs(`
+		typed_set -> ..first; ..second; ..unnamed;;
+		..first => nrel_name: ..first_name;;
+		..first_name = [first];;
+		..second => nrel_name: ..second_name;;
+		..second_name = [second];;
+	`)
+
+	var calls atomic.Int64
+	batchLoader := sc.TemplateBatchLoader[typedMember](typedMemberTemplate(addrs["nrel_name"]))
+	loader := func(c *sc.ScClient, members []sc.ScAddr) ([]typedMember, []error) {
+		calls.Add(1)
+		return batchLoader(c, members)
+	}
+
+	set, err := sc.NewScTypedSetBatch[typedMember](kit.Client, addrs["typed_set"], loader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var failed, removed []sc.ScAddr
+	set.OnError = func(addr sc.ScAddr, err error) {
+		mu.Lock()
+		failed = append(failed, addr)
+		mu.Unlock()
+	}
+	set.OnRemove = func(addr sc.ScAddr, item typedMember) {
+		mu.Lock()
+		removed = append(removed, addr)
+		mu.Unlock()
+	}
+	if err := set.Initialize(context.Background()); err != nil {
+		t.Fatalf("failed to initialize set: %v", err)
+	}
+	defer set.Close()
+
+	if calls.Load() != 1 {
+		t.Errorf("expected existing members to be loaded with one call, got %d", calls.Load())
+	}
+	if first, loaded := set.Get(addrs["..first"]); !loaded || first.Name != "first" || !first.Addr.Equal(addrs["..first"]) {
+		t.Errorf("unexpected first member %+v", first)
+	}
+	if second, loaded := set.Get(addrs["..second"]); !loaded || second.Name != "second" {
+		t.Errorf("unexpected second member %+v", second)
+	}
+	mu.Lock()
+	if len(failed) != 1 || !failed[0].Equal(addrs["..unnamed"]) {
+		t.Errorf("expected member without name to fail, failed: %v", failed)
+	}
+	mu.Unlock()
+
+	if _, err := set.Set.RemoveItem(addrs["..first"]); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(removed) == 1
+	})
+	if _, loaded := set.Get(addrs["..first"]); loaded || set.Len() != 1 {
+		t.Errorf("removed member is still loaded, %d loaded", set.Len())
+	}
+}
+
+func TestScTypedSetDropsLoadOfRemovedMember(t *testing.T) {
+	kit := agenttest.New(t)
+	setAddr := kit.Keynode("loading_set", sc.ScTypeNodeConst)
+
+	loading := make(chan sc.ScAddr, 1)
+	release := make(chan struct{})
+	var added atomic.Int64
+	set, err := sc.NewScTypedSet[sc.ScAddr](kit.Client, setAddr, func(c *sc.ScClient, addr sc.ScAddr) (sc.ScAddr, error) {
+		loading <- addr
+		<-release
+		return addr, nil
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set.Set.CoalesceDelay = 0
+	set.OnAdd = func(addr sc.ScAddr, item sc.ScAddr) {
+		added.Add(1)
+	}
+	if err := set.Initialize(context.Background()); err != nil {
+		t.Fatalf("failed to initialize set: %v", err)
+	}
+	defer set.Close()
+
+	member := kit.LoadSCs(`loading_set -> ..member;;`)["..member"]
+	select {
+	case <-loading:
+	case <-time.After(5 * time.Second):
+		t.Fatal("member is not loaded")
+	}
+	if _, err := set.Set.RemoveItem(member); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return !set.Set.Contains(member) })
+	close(release)
+
+	time.Sleep(50 * time.Millisecond)
+	if _, loaded := set.Get(member); loaded || added.Load() != 0 {
+		t.Errorf("value of removed member is kept, added %d", added.Load())
+	}
+}